@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
 	"github.com/antihax/optional"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
@@ -14,20 +16,19 @@ import (
 	gateapi "github.com/spinnaker/spin/gateapi"
 )
 
-func GetApplication(client *gate.GatewayClient, applicationName string, dest interface{}) error {
+// GetApplication fetches the application with applicationName and decodes it
+// into dest. Returns an error that satisfies errors.IsNotFound if the
+// application does not exist.
+func GetApplication(ctx context.Context, client *gate.GatewayClient, applicationName string, dest interface{}) error {
+	ctx = withClientContext(ctx, client)
+
 	opts := &gateapi.ApplicationControllerApiGetApplicationUsingGETOpts{}
 	opts.Expand = optional.NewBool(false)
-	app, resp, err := client.ApplicationControllerApi.GetApplicationUsingGET(client.Context, applicationName, opts)
-	if resp != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return fmt.Errorf("Application '%s' not found\n", applicationName)
-		} else if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("Encountered an error getting application, status code: %d\n", resp.StatusCode)
-		}
-	}
-
-	if err != nil {
-		return err
+	app, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.ApplicationControllerApi.GetApplicationUsingGET(ctx, applicationName, opts)
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return errors.NewResponseError(resp, err)
 	}
 
 	if err := mapstructure.Decode(app, dest); err != nil {
@@ -37,63 +38,121 @@ func GetApplication(client *gate.GatewayClient, applicationName string, dest int
 	return nil
 }
 
-func CreateApplication(client *gate.GatewayClient, applicationData *schema.ResourceData) error {
-	applicationName := applicationData.Get("application").(string)
-	app := map[string]interface{}{
-		"instancePort":   80,
-		"name":           applicationName,
-		"email":          applicationData.Get("email").(string),
-		"repoType":       applicationData.Get("repo_type").(string),
-		"repoProjectKey": applicationData.Get("repo_project_key").(string),
-		"repoSlug":       applicationData.Get("repo_slug").(string),
-	}
+// ListApplications fetches all applications and decodes them into dest,
+// which should be a pointer to a slice. If account or owner is not empty, only
+// applications deployed to the account or owned by the email are returned.
+func ListApplications(ctx context.Context, client *gate.GatewayClient, account, owner string, dest interface{}) error {
+	ctx = withClientContext(ctx, client)
 
-	createAppTask := map[string]interface{}{
-		"job":         []interface{}{map[string]interface{}{"type": "createApplication", "application": app}},
-		"application": applicationName,
-		"description": fmt.Sprintf("Create Application: %s", applicationName),
+	opts := &gateapi.ApplicationControllerApiGetAllApplicationsUsingGETOpts{}
+	if account != "" {
+		opts.Account = optional.NewString(account)
+	}
+	if owner != "" {
+		opts.Owner = optional.NewString(owner)
 	}
 
-	ref, _, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.TaskControllerApi.TaskUsingPOST1(client.Context, createAppTask)
+	var apps []interface{}
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		a, resp, err := client.ApplicationControllerApi.GetAllApplicationsUsingGET(ctx, opts)
+		apps = a
+		return nil, resp, err
 	})
-	if err != nil {
-		return err
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return errors.NewResponseError(resp, err)
 	}
 
-	toks := strings.Split(ref["ref"].(string), "/")
-	id := toks[len(toks)-1]
+	return mapstructure.Decode(apps, dest)
+}
+
+// applicationAttributes maps the schema fields of the application resource to
+// the Spinnaker application attributes they are sent as. The optional expand
+// func converts the schema value into the format expected by Spinnaker.
+var applicationAttributes = []struct {
+	field     string
+	attribute string
+	expand    func(interface{}) interface{}
+}{
+	{field: "email", attribute: "email"},
+	{field: "repo_type", attribute: "repoType"},
+	{field: "repo_project_key", attribute: "repoProjectKey"},
+	{field: "repo_slug", attribute: "repoSlug"},
+	{field: "description", attribute: "description"},
+	{field: "cloud_providers", attribute: "cloudProviders", expand: joinStringList},
+	{field: "instance_port", attribute: "instancePort"},
+	{field: "aliases", attribute: "aliases", expand: joinStringList},
+	{field: "enable_restart_running_executions", attribute: "enableRestartRunningExecutions"},
+	{field: "platform_health_only", attribute: "platformHealthOnly"},
+	{field: "platform_health_only_show_override", attribute: "platformHealthOnlyShowOverride"},
+	{field: "traffic_guards", attribute: "trafficGuards", expand: expandTrafficGuards},
+	{field: "data_sources", attribute: "dataSources", expand: expandDataSources},
+	{field: "custom_banners", attribute: "customBanners", expand: expandCustomBanners},
+	{field: "permissions", attribute: "permissions", expand: expandPermissions},
+}
 
-	task, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.TaskControllerApi.GetTaskUsingGET1(client.Context, id)
-	})
+// CreateApplication submits a createApplication task for the application
+// described by applicationData and waits up to timeout for it to complete.
+func CreateApplication(ctx context.Context, client *gate.GatewayClient, applicationData *schema.ResourceData, timeout time.Duration) error {
+	applicationName := applicationData.Get("application").(string)
+	app := expandApplication(applicationData, false)
 
-	attempts := 0
-	for (task == nil || !taskCompleted(task)) && attempts < 5 {
-		toks := strings.Split(ref["ref"].(string), "/")
-		id := toks[len(toks)-1]
+	return saveApplication(ctx, client, "createApplication", fmt.Sprintf("Create Application: %s", applicationName), app, timeout)
+}
 
-		task, resp, err = retry(func() (map[string]interface{}, *http.Response, error) {
-			return client.TaskControllerApi.GetTaskUsingGET1(client.Context, id)
-		})
-		attempts += 1
-		time.Sleep(time.Duration(attempts*attempts) * time.Second)
+// UpdateApplication submits an updateApplication task containing only the
+// attributes of applicationData that have changed and waits up to timeout for
+// it to complete. Spinnaker merges these into the existing application,
+// leaving attributes that are not managed by Terraform untouched.
+func UpdateApplication(ctx context.Context, client *gate.GatewayClient, applicationData *schema.ResourceData, timeout time.Duration) error {
+	applicationName := applicationData.Get("application").(string)
+	app := expandApplication(applicationData, true)
+
+	return saveApplication(ctx, client, "updateApplication", fmt.Sprintf("Update Application: %s", applicationName), app, timeout)
+}
+
+func expandApplication(applicationData *schema.ResourceData, onlyChanged bool) map[string]interface{} {
+	app := map[string]interface{}{
+		"name": applicationData.Get("application").(string),
 	}
 
-	if err != nil {
-		return err
+	for _, attr := range applicationAttributes {
+		if onlyChanged && !applicationData.HasChange(attr.field) {
+			continue
+		}
+
+		value := applicationData.Get(attr.field)
+		if attr.expand != nil {
+			value = attr.expand(value)
+		}
+
+		app[attr.attribute] = value
 	}
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("Encountered an error saving application, status code: %d\n", resp.StatusCode)
+
+	return app
+}
+
+func saveApplication(ctx context.Context, client *gate.GatewayClient, taskType, description string, app map[string]interface{}, timeout time.Duration) error {
+	applicationName := app["name"].(string)
+
+	saveAppTask := map[string]interface{}{
+		"job":         []interface{}{map[string]interface{}{"type": taskType, "application": app}},
+		"application": applicationName,
+		"description": description,
 	}
-	if !taskSucceeded(task) {
-		return fmt.Errorf("Encountered an error saving application, task output was: %v\n", task)
+
+	if _, err := SubmitTaskAndWait(ctx, client, saveAppTask, DefaultTaskPollInterval, timeout); err != nil {
+		return fmt.Errorf("%s for application %q failed: %w", taskType, applicationName, err)
 	}
 
 	return nil
 }
 
-func DeleteAppliation(client *gate.GatewayClient, applicationName string) error {
+// DeleteApplication submits a deleteApplication task and waits up to timeout
+// for it to complete. The error contains the failure reason reported by Orca
+// if the task did not succeed, e.g. because the application still has server
+// groups.
+func DeleteApplication(ctx context.Context, client *gate.GatewayClient, applicationName string, timeout time.Duration) error {
 	jobSpec := map[string]interface{}{
 		"type": "deleteApplication",
 		"application": map[string]interface{}{
@@ -107,46 +166,88 @@ func DeleteAppliation(client *gate.GatewayClient, applicationName string) error
 		"description": fmt.Sprintf("Delete Application: %s", applicationName),
 	}
 
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.TaskControllerApi.TaskUsingPOST1(client.Context, deleteAppTask)
-	})
-	if err != nil {
-		return err
+	if _, err := SubmitTaskAndWait(ctx, client, deleteAppTask, DefaultTaskPollInterval, timeout); err != nil {
+		return fmt.Errorf("deleteApplication for application %q failed: %w", applicationName, err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Encountered an error deleting application, status code: %d\n", resp.StatusCode)
+	return nil
+}
+
+// joinStringList joins a list of strings into the comma separated format
+// Spinnaker uses for attributes like cloudProviders and aliases.
+func joinStringList(v interface{}) interface{} {
+	items := v.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, item.(string))
 	}
 
-	return nil
+	return strings.Join(values, ",")
 }
 
-func taskCompleted(task map[string]interface{}) bool {
-	taskStatus, exists := task["status"]
-	if !exists {
-		return false
+func expandTrafficGuards(v interface{}) interface{} {
+	guards := v.([]interface{})
+	result := make([]interface{}, 0, len(guards))
+
+	for _, g := range guards {
+		guard := g.(map[string]interface{})
+		result = append(result, map[string]interface{}{
+			"account":  guard["account"].(string),
+			"location": guard["location"].(string),
+			"stack":    guard["stack"].(string),
+			"detail":   guard["detail"].(string),
+			"enabled":  guard["enabled"].(bool),
+		})
 	}
 
-	COMPLETED := [...]string{"SUCCEEDED", "STOPPED", "SKIPPED", "TERMINAL", "FAILED_CONTINUE"}
-	for _, status := range COMPLETED {
-		if taskStatus == status {
-			return true
+	return result
+}
+
+func expandDataSources(v interface{}) interface{} {
+	dataSources := v.([]interface{})
+	if len(dataSources) == 0 || dataSources[0] == nil {
+		return map[string]interface{}{
+			"enabled":  []interface{}{},
+			"disabled": []interface{}{},
 		}
 	}
-	return false
+
+	sources := dataSources[0].(map[string]interface{})
+
+	return map[string]interface{}{
+		"enabled":  sources["enabled"].([]interface{}),
+		"disabled": sources["disabled"].([]interface{}),
+	}
 }
 
-func taskSucceeded(task map[string]interface{}) bool {
-	taskStatus, exists := task["status"]
-	if !exists {
-		return false
+func expandCustomBanners(v interface{}) interface{} {
+	banners := v.([]interface{})
+	result := make([]interface{}, 0, len(banners))
+
+	for _, b := range banners {
+		banner := b.(map[string]interface{})
+		result = append(result, map[string]interface{}{
+			"text":            banner["text"].(string),
+			"enabled":         banner["enabled"].(bool),
+			"backgroundColor": banner["background_color"].(string),
+			"textColor":       banner["text_color"].(string),
+		})
 	}
 
-	SUCCESSFUL := [...]string{"SUCCEEDED", "STOPPED", "SKIPPED"}
-	for _, status := range SUCCESSFUL {
-		if taskStatus == status {
-			return true
-		}
+	return result
+}
+
+func expandPermissions(v interface{}) interface{} {
+	permissions := v.([]interface{})
+	if len(permissions) == 0 || permissions[0] == nil {
+		return map[string]interface{}{}
+	}
+
+	perms := permissions[0].(map[string]interface{})
+
+	return map[string]interface{}{
+		"READ":    perms["read"].(*schema.Set).List(),
+		"WRITE":   perms["write"].(*schema.Set).List(),
+		"EXECUTE": perms["execute"].(*schema.Set).List(),
 	}
-	return false
 }
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+// GetCurrentUser returns the name of the user Gate authenticates the client
+// as. This is "anonymous" if Gate does not require authentication.
+func GetCurrentUser(ctx context.Context, client *gate.GatewayClient) (string, error) {
+	ctx = withClientContext(ctx, client)
+
+	var username string
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		user, resp, err := client.AuthControllerApi.UserUsingGET(ctx)
+		username = user.Username
+		return nil, resp, err
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return "", errors.NewResponseError(resp, err)
+	}
+
+	return username, nil
+}
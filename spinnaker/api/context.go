@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+// requestContext is a context that is canceled together with the context of
+// the Terraform operation it was derived from, but falls back to the values of
+// the long-lived client context. The client context carries the credentials
+// and the retry policy configured on the provider.
+type requestContext struct {
+	context.Context
+
+	values context.Context
+}
+
+// Value implements context.Context.
+func (c *requestContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+
+	return c.values.Value(key)
+}
+
+// withClientContext returns a context for calls to Gate that is canceled when
+// ctx is done and carries the values of client.Context.
+func withClientContext(ctx context.Context, client *gate.GatewayClient) context.Context {
+	if client.Context == nil {
+		return ctx
+	}
+
+	return &requestContext{Context: ctx, values: client.Context}
+}
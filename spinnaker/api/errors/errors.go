@@ -11,6 +11,11 @@ import (
 
 var pipelineAlreadyExistsRegexp = regexp.MustCompile(`.*A pipeline with name .* already exists.*`)
 
+// ErrNotFound indicates that a requested entity does not exist in cases where
+// Gate does not respond with http.StatusNotFound, e.g. because it returns an
+// empty list instead.
+var ErrNotFound = errors.New("not found")
+
 // IsPipelineAlreadyExists returns true if the error indicates that a pipeline
 // already exists.
 func IsPipelineAlreadyExists(err error) bool {
@@ -21,9 +26,10 @@ func IsPipelineAlreadyExists(err error) bool {
 	return pipelineAlreadyExistsRegexp.MatchString(err.Error())
 }
 
-// IsNotFound returns true if err resembles an HTTP NotFound error.
+// IsNotFound returns true if err resembles an HTTP NotFound error or wraps
+// ErrNotFound.
 func IsNotFound(err error) bool {
-	return HasCode(http.StatusNotFound, err)
+	return errors.Is(err, ErrNotFound) || HasCode(http.StatusNotFound, err)
 }
 
 // HasCode returns true if err resembles an HTTP error with status code.
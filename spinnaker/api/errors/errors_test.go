@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -24,4 +25,5 @@ func TestIsNotFound(t *testing.T) {
 	require.False(t, IsNotFound(err))
 	require.False(t, IsNotFound(respErr))
 	require.True(t, IsNotFound(notFoundErr))
+	require.True(t, IsNotFound(fmt.Errorf("pipeline %q %w", "foo", ErrNotFound)))
 }
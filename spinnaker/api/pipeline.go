@@ -1,16 +1,22 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/antihax/optional"
 	"github.com/mitchellh/mapstructure"
 	gate "github.com/spinnaker/spin/cmd/gateclient"
+	gateapi "github.com/spinnaker/spin/gateapi"
 )
 
-func CreatePipeline(client *gate.GatewayClient, pipeline interface{}) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		resp, err := client.PipelineControllerApi.SavePipelineUsingPOST(client.Context, pipeline, nil)
+func CreatePipeline(ctx context.Context, client *gate.GatewayClient, pipeline interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		resp, err := client.PipelineControllerApi.SavePipelineUsingPOST(ctx, pipeline, nil)
 
 		return nil, resp, err
 	})
@@ -21,10 +27,12 @@ func CreatePipeline(client *gate.GatewayClient, pipeline interface{}) error {
 	return nil
 }
 
-func GetPipeline(client *gate.GatewayClient, applicationName, pipelineName string, dest interface{}) (map[string]interface{}, error) {
-	payload, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
+func GetPipeline(ctx context.Context, client *gate.GatewayClient, applicationName, pipelineName string, dest interface{}) (map[string]interface{}, error) {
+	ctx = withClientContext(ctx, client)
+
+	payload, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
 		return client.ApplicationControllerApi.GetPipelineConfigUsingGET(
-			client.Context,
+			ctx,
 			applicationName,
 			pipelineName,
 		)
@@ -40,9 +48,106 @@ func GetPipeline(client *gate.GatewayClient, applicationName, pipelineName strin
 	return payload, nil
 }
 
-func UpdatePipeline(client *gate.GatewayClient, pipelineID string, pipeline interface{}) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.PipelineControllerApi.UpdatePipelineUsingPUT(client.Context, pipelineID, pipeline)
+// GetPipelineByID fetches the latest revision of the pipeline config with
+// pipelineID from the pipeline config history and decodes it into dest.
+// Returns an error wrapping errors.ErrNotFound if no such pipeline exists.
+func GetPipelineByID(ctx context.Context, client *gate.GatewayClient, pipelineID string, dest interface{}) (map[string]interface{}, error) {
+	history, err := GetPipelineHistory(ctx, client, pipelineID, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(history) == 0 {
+		return nil, fmt.Errorf("pipeline with id %q %w", pipelineID, errors.ErrNotFound)
+	}
+
+	if err := mapstructure.Decode(history[0], dest); err != nil {
+		return nil, err
+	}
+
+	return history[0], nil
+}
+
+// GetPipelineHistory fetches up to limit revisions of the pipeline config
+// with pipelineID, newest first. If dest is not nil, the revisions are
+// decoded into it, which should be a pointer to a slice.
+func GetPipelineHistory(ctx context.Context, client *gate.GatewayClient, pipelineID string, limit int, dest interface{}) ([]map[string]interface{}, error) {
+	ctx = withClientContext(ctx, client)
+
+	var history []interface{}
+
+	opts := &gateapi.PipelineConfigControllerApiGetPipelineConfigHistoryUsingGETOpts{
+		Limit: optional.NewInt32(int32(limit)),
+	}
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		h, resp, err := client.PipelineConfigControllerApi.GetPipelineConfigHistoryUsingGET(ctx, pipelineID, opts)
+		history = h
+		return nil, resp, err
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return nil, errors.NewResponseError(resp, err)
+	}
+
+	payload := make([]map[string]interface{}, 0, len(history))
+
+	for _, item := range history {
+		revision, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected pipeline config history entry: %v", item)
+		}
+
+		payload = append(payload, revision)
+	}
+
+	if dest != nil {
+		if err := mapstructure.Decode(payload, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+// ListPipelines fetches the configs of all pipelines of the application and
+// decodes them into dest, which should be a pointer to a slice.
+func ListPipelines(ctx context.Context, client *gate.GatewayClient, applicationName string, dest interface{}) ([]map[string]interface{}, error) {
+	ctx = withClientContext(ctx, client)
+
+	var list []interface{}
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		l, resp, err := client.ApplicationControllerApi.GetPipelineConfigsForApplicationUsingGET(ctx, applicationName)
+		list = l
+		return nil, resp, err
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return nil, errors.NewResponseError(resp, err)
+	}
+
+	payload := make([]map[string]interface{}, 0, len(list))
+
+	for _, item := range list {
+		pipeline, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected pipeline config: %v", item)
+		}
+
+		payload = append(payload, pipeline)
+	}
+
+	if err := mapstructure.Decode(payload, dest); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func UpdatePipeline(ctx context.Context, client *gate.GatewayClient, pipelineID string, pipeline interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.PipelineControllerApi.UpdatePipelineUsingPUT(ctx, pipelineID, pipeline)
 	})
 	if err != nil || resp.StatusCode != http.StatusOK {
 		return errors.NewResponseError(resp, err)
@@ -51,10 +156,12 @@ func UpdatePipeline(client *gate.GatewayClient, pipelineID string, pipeline inte
 	return nil
 }
 
-func DeletePipeline(client *gate.GatewayClient, applicationName, pipelineName string) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
+func DeletePipeline(ctx context.Context, client *gate.GatewayClient, applicationName, pipelineName string) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
 		resp, err := client.PipelineControllerApi.DeletePipelineUsingDELETE(
-			client.Context,
+			ctx,
 			applicationName,
 			pipelineName,
 		)
@@ -66,15 +173,3 @@ func DeletePipeline(client *gate.GatewayClient, applicationName, pipelineName st
 
 	return nil
 }
-
-// RecreatePipeline is a convenience function for deleting and subsequently
-// recreating a pipeline. It will return an error if either of the delete and
-// create operations fails.
-func RecreatePipeline(client *gate.GatewayClient, applicationName, pipelineName string, pipeline interface{}) error {
-	err := DeletePipeline(client, applicationName, pipelineName)
-	if err != nil {
-		return err
-	}
-
-	return CreatePipeline(client, pipeline)
-}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -12,9 +13,11 @@ const (
 	ErrCodeNoSuchEntityException = "NoSuchEntityException"
 )
 
-func CreatePipelineTemplate(client *gate.GatewayClient, template interface{}) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		resp, err := client.PipelineTemplatesControllerApi.CreateUsingPOST(client.Context, template)
+func CreatePipelineTemplate(ctx context.Context, client *gate.GatewayClient, template interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		resp, err := client.PipelineTemplatesControllerApi.CreateUsingPOST(ctx, template)
 
 		return nil, resp, err
 	})
@@ -29,9 +32,11 @@ func CreatePipelineTemplate(client *gate.GatewayClient, template interface{}) er
 	return nil
 }
 
-func GetPipelineTemplate(client *gate.GatewayClient, templateID string, dest interface{}) error {
-	successPayload, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.PipelineTemplatesControllerApi.GetUsingGET(client.Context, templateID)
+func GetPipelineTemplate(ctx context.Context, client *gate.GatewayClient, templateID string, dest interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	successPayload, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.PipelineTemplatesControllerApi.GetUsingGET(ctx, templateID)
 	})
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
@@ -60,9 +65,11 @@ func GetPipelineTemplate(client *gate.GatewayClient, templateID string, dest int
 	return nil
 }
 
-func DeletePipelineTemplate(client *gate.GatewayClient, templateID string) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.PipelineTemplatesControllerApi.DeleteUsingDELETE(client.Context, templateID, nil)
+func DeletePipelineTemplate(ctx context.Context, client *gate.GatewayClient, templateID string) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.PipelineTemplatesControllerApi.DeleteUsingDELETE(ctx, templateID, nil)
 	})
 	if err != nil {
 		return err
@@ -77,9 +84,11 @@ func DeletePipelineTemplate(client *gate.GatewayClient, templateID string) error
 	return nil
 }
 
-func UpdatePipelineTemplate(client *gate.GatewayClient, templateID string, template interface{}) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		resp, err := client.PipelineTemplatesControllerApi.UpdateUsingPOST(client.Context, templateID, template, nil)
+func UpdatePipelineTemplate(ctx context.Context, client *gate.GatewayClient, templateID string, template interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		resp, err := client.PipelineTemplatesControllerApi.UpdateUsingPOST(ctx, templateID, template, nil)
 
 		return nil, resp, err
 	})
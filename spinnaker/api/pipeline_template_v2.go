@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
@@ -10,10 +11,18 @@ import (
 	gateapi "github.com/spinnaker/spin/gateapi"
 )
 
-// CreatePipelineTemplateV2 creates a pipeline template.
-func CreatePipelineTemplateV2(client *gate.GatewayClient, template *PipelineTemplateV2) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.V2PipelineTemplatesControllerApi.CreateUsingPOST1(client.Context, template, nil)
+// CreatePipelineTemplateV2 creates a pipeline template. If tag is not empty,
+// the template is published under that tag.
+func CreatePipelineTemplateV2(ctx context.Context, client *gate.GatewayClient, template *PipelineTemplateV2, tag string) error {
+	ctx = withClientContext(ctx, client)
+
+	opts := &gateapi.V2PipelineTemplatesControllerApiCreateUsingPOST1Opts{}
+	if tag != "" {
+		opts.Tag = optional.NewString(tag)
+	}
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.V2PipelineTemplatesControllerApi.CreateUsingPOST1(ctx, template, opts)
 	})
 	if err != nil || (resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated) {
 		return errors.NewResponseError(resp, err)
@@ -22,10 +31,21 @@ func CreatePipelineTemplateV2(client *gate.GatewayClient, template *PipelineTemp
 	return nil
 }
 
-// GetPipelineTemplateV2 fetches the pipeline template with templateID.
-func GetPipelineTemplateV2(client *gate.GatewayClient, templateID string) (*PipelineTemplateV2, error) {
-	payload, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.V2PipelineTemplatesControllerApi.GetUsingGET2(client.Context, templateID, nil)
+// GetPipelineTemplateV2 fetches the version of the pipeline template with
+// templateID that has the given digest or tag. The latest version is fetched
+// if neither is set.
+func GetPipelineTemplateV2(ctx context.Context, client *gate.GatewayClient, templateID, tag, digest string) (*PipelineTemplateV2, error) {
+	ctx = withClientContext(ctx, client)
+
+	opts := &gateapi.V2PipelineTemplatesControllerApiGetUsingGET2Opts{}
+	if digest != "" {
+		opts.Digest = optional.NewString(digest)
+	} else if tag != "" {
+		opts.Tag = optional.NewString(tag)
+	}
+
+	payload, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.V2PipelineTemplatesControllerApi.GetUsingGET2(ctx, templateID, opts)
 	})
 	if err != nil || resp.StatusCode != http.StatusOK {
 		return nil, errors.NewResponseError(resp, err)
@@ -42,7 +62,9 @@ func GetPipelineTemplateV2(client *gate.GatewayClient, templateID string) (*Pipe
 
 // DeletePipelineTemplateV2 deletes the pipeline template with templateID.
 // Either digest or tag can be set on a delete request, but not both.
-func DeletePipelineTemplateV2(client *gate.GatewayClient, templateID, tag, digest string) error {
+func DeletePipelineTemplateV2(ctx context.Context, client *gate.GatewayClient, templateID, tag, digest string) error {
+	ctx = withClientContext(ctx, client)
+
 	opts := &gateapi.V2PipelineTemplatesControllerApiDeleteUsingDELETE1Opts{}
 	if digest != "" {
 		opts.Digest = optional.NewString(digest)
@@ -50,8 +72,8 @@ func DeletePipelineTemplateV2(client *gate.GatewayClient, templateID, tag, diges
 		opts.Tag = optional.NewString(tag)
 	}
 
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.V2PipelineTemplatesControllerApi.DeleteUsingDELETE1(client.Context, templateID, opts)
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.V2PipelineTemplatesControllerApi.DeleteUsingDELETE1(ctx, templateID, opts)
 	})
 	if err != nil || (resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent) {
 		return errors.NewResponseError(resp, err)
@@ -61,10 +83,18 @@ func DeletePipelineTemplateV2(client *gate.GatewayClient, templateID, tag, diges
 }
 
 // UpdatePipelineTemplateV2 updates the pipeline template with templateID with
-// the data in template.
-func UpdatePipelineTemplateV2(client *gate.GatewayClient, template *PipelineTemplateV2) error {
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		return client.V2PipelineTemplatesControllerApi.UpdateUsingPOST1(client.Context, template.ID, template, nil)
+// the data in template. If tag is not empty, the template is published under
+// that tag.
+func UpdatePipelineTemplateV2(ctx context.Context, client *gate.GatewayClient, template *PipelineTemplateV2, tag string) error {
+	ctx = withClientContext(ctx, client)
+
+	opts := &gateapi.V2PipelineTemplatesControllerApiUpdateUsingPOST1Opts{}
+	if tag != "" {
+		opts.Tag = optional.NewString(tag)
+	}
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.V2PipelineTemplatesControllerApi.UpdateUsingPOST1(ctx, template.ID, template, opts)
 	})
 	if err != nil || (resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated) {
 		return errors.NewResponseError(resp, err)
@@ -75,11 +105,13 @@ func UpdatePipelineTemplateV2(client *gate.GatewayClient, template *PipelineTemp
 
 // ListPipelineTemplateV2Versions lists versions of all available pipeline
 // templates. The resulting map is keyed by template ID.
-func ListPipelineTemplateV2Versions(client *gate.GatewayClient) (map[string][]*PipelineTemplateV2Version, error) {
+func ListPipelineTemplateV2Versions(ctx context.Context, client *gate.GatewayClient) (map[string][]*PipelineTemplateV2Version, error) {
+	ctx = withClientContext(ctx, client)
+
 	var payload interface{}
 
-	_, resp, err := retry(func() (map[string]interface{}, *http.Response, error) {
-		v, resp, err := client.V2PipelineTemplatesControllerApi.ListVersionsUsingGET(client.Context, nil)
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		v, resp, err := client.V2PipelineTemplatesControllerApi.ListVersionsUsingGET(ctx, nil)
 		payload = v
 		return nil, resp, err
 	})
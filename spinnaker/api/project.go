@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/mitchellh/mapstructure"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+// projectTaskApplication is the application project tasks are run in, as
+// projects do not belong to an application.
+const projectTaskApplication = "spinnaker"
+
+// GetProject fetches the project with the given ID or name and decodes it
+// into dest. Returns an error that satisfies errors.IsNotFound if the project
+// does not exist.
+func GetProject(ctx context.Context, client *gate.GatewayClient, projectIDOrName string, dest interface{}) error {
+	ctx = withClientContext(ctx, client)
+
+	project, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.ProjectControllerApi.GetUsingGET1(ctx, projectIDOrName)
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return errors.NewResponseError(resp, err)
+	}
+
+	return mapstructure.Decode(project, dest)
+}
+
+// SaveProject submits an upsertProject task for project and waits up to
+// timeout for it to complete. The project is created if it does not have an
+// id and updated otherwise.
+func SaveProject(ctx context.Context, client *gate.GatewayClient, project map[string]interface{}, timeout time.Duration) error {
+	projectName := project["name"].(string)
+
+	description := fmt.Sprintf("Create project: %s", projectName)
+	if _, ok := project["id"]; ok {
+		description = fmt.Sprintf("Update project: %s", projectName)
+	}
+
+	task := map[string]interface{}{
+		"job":         []interface{}{map[string]interface{}{"type": "upsertProject", "project": project}},
+		"application": projectTaskApplication,
+		"project":     projectName,
+		"description": description,
+	}
+
+	if _, err := SubmitTaskAndWait(ctx, client, task, DefaultTaskPollInterval, timeout); err != nil {
+		return fmt.Errorf("upsertProject for project %q failed: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// DeleteProject submits a deleteProject task for the project with projectID
+// and waits up to timeout for it to complete.
+func DeleteProject(ctx context.Context, client *gate.GatewayClient, projectID, projectName string, timeout time.Duration) error {
+	task := map[string]interface{}{
+		"job": []interface{}{map[string]interface{}{
+			"type":    "deleteProject",
+			"project": map[string]interface{}{"id": projectID},
+		}},
+		"application": projectTaskApplication,
+		"project":     projectName,
+		"description": fmt.Sprintf("Delete project: %s", projectName),
+	}
+
+	if _, err := SubmitTaskAndWait(ctx, client, task, DefaultTaskPollInterval, timeout); err != nil {
+		return fmt.Errorf("deleteProject for project %q failed: %w", projectName, err)
+	}
+
+	return nil
+}
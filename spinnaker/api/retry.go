@@ -1,41 +1,195 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 )
 
 const (
-	// maxRetries defines the number of times an operation should be retried in
-	// the worst case.
-	maxRetries = 5
+	// defaultMaxAttempts defines the number of times an operation is
+	// attempted in the worst case.
+	defaultMaxAttempts = 6
 
-	// retryInterval specified the time to wait between two retries.
-	retryInterval = 2 * time.Second
+	// defaultInitialInterval is the time to wait before the first retry.
+	defaultInitialInterval = 2 * time.Second
+
+	// defaultMaxInterval caps the time to wait between two retries.
+	defaultMaxInterval = 30 * time.Second
+
+	// defaultMultiplier is the factor the wait interval is multiplied with
+	// after each retry.
+	defaultMultiplier = 1.5
+
+	// randomizationFactor adds jitter of +/- 50% to each wait interval.
+	randomizationFactor = 0.5
 )
 
+// RetryPolicy configures how API calls failing with transient errors are
+// retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times an operation is attempted,
+	// including the first attempt.
+	MaxAttempts int
+	// InitialInterval is the time to wait before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the time to wait between two retries.
+	MaxInterval time.Duration
+	// Multiplier is the factor the wait interval grows by after each retry.
+	Multiplier float64
+	// RetryOnStatusCodes lists the HTTP status codes that are considered
+	// transient. If empty, 429 and all 500-range status codes are retried.
+	RetryOnStatusCodes []int
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used if none was configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     defaultMaxAttempts,
+		InitialInterval: defaultInitialInterval,
+		MaxInterval:     defaultMaxInterval,
+		Multiplier:      defaultMultiplier,
+	}
+}
+
+// shouldRetry returns true if an operation that resulted in a response with
+// statusCode should be retried.
+func (p RetryPolicy) shouldRetry(statusCode int) bool {
+	// Invalid response codes like 0 and 999 are always retried.
+	if statusCode == 0 || statusCode > 599 {
+		return true
+	}
+
+	if len(p.RetryOnStatusCodes) == 0 {
+		return statusCode == http.StatusTooManyRequests || statusCode >= 500
+	}
+
+	for _, code := range p.RetryOnStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) backOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.MaxInterval = p.MaxInterval
+	b.Multiplier = p.Multiplier
+	b.RandomizationFactor = randomizationFactor
+	b.MaxElapsedTime = 0
+
+	maxRetries := p.MaxAttempts - 1
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return backoff.WithMaxRetries(b, uint64(maxRetries))
+}
+
+type retryPolicyKey struct{}
+
+// ContextWithRetryPolicy returns a copy of ctx that carries policy. API calls
+// made with the returned context are retried according to policy.
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryPolicyFromContext(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+
+	return DefaultRetryPolicy()
+}
+
 // operation is a func that performs an API call and returns the response json
 // data as map, the http response and an error.
 type operation func() (map[string]interface{}, *http.Response, error)
 
-// retry retries an operation using constant backoff.
-func retry(fn operation) (data map[string]interface{}, resp *http.Response, err error) {
+// retry retries an operation using exponential backoff with jitter according
+// to the RetryPolicy carried by ctx. Retry-After headers sent along with 429
+// and 503 responses are honored.
+func retry(ctx context.Context, fn operation) (data map[string]interface{}, resp *http.Response, err error) {
+	policy := retryPolicyFromContext(ctx)
+	b := &retryAfterBackOff{BackOff: policy.backOff()}
+
 	err = backoff.Retry(func() error {
 		data, resp, err = fn()
 
-		// Check the response code. We retry on 500-range responses to allow
-		// the server time to recover, as 500's are typically not permanent
-		// errors and may relate to outages on the server side. This will catch
-		// invalid response codes as well, like 0 and 999.
-		if resp != nil && (resp.StatusCode == 0 || resp.StatusCode >= 500) {
-			return errors.New(http.StatusText(resp.StatusCode))
+		if resp == nil {
+			// Network errors are considered transient.
+			return err
 		}
 
-		return err
-	}, backoff.WithMaxRetries(backoff.NewConstantBackOff(retryInterval), maxRetries))
+		// Check the response code. We retry on configured status codes to
+		// allow the server time to recover, e.g. from 500's which are
+		// typically not permanent errors and may relate to outages on the
+		// server side.
+		if policy.shouldRetry(resp.StatusCode) {
+			b.retryAfter = parseRetryAfter(resp)
+
+			if err == nil {
+				err = errors.New(http.StatusText(resp.StatusCode))
+			}
+
+			return err
+		}
+
+		return backoff.Permanent(err)
+	}, backoff.WithContext(b, ctx))
 
 	return
 }
+
+// retryAfterBackOff waits for at least the duration requested by the
+// Retry-After header of the last response.
+type retryAfterBackOff struct {
+	backoff.BackOff
+
+	retryAfter time.Duration
+}
+
+// NextBackOff implements backoff.BackOff.
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next != backoff.Stop && b.retryAfter > next {
+		next = b.retryAfter
+	}
+
+	b.retryAfter = 0
+
+	return next
+}
+
+// parseRetryAfter returns the duration requested by the Retry-After header of
+// 429 and 503 responses. The header value may either be a number of seconds
+// or an HTTP date. Returns 0 if the header is absent or invalid.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+	"github.com/stretchr/testify/require"
+)
+
+func testRetryPolicy(codes ...int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:        3,
+		InitialInterval:    time.Millisecond,
+		MaxInterval:        time.Millisecond,
+		Multiplier:         1,
+		RetryOnStatusCodes: codes,
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := map[string]struct {
+		policy       RetryPolicy
+		statusCodes  []int
+		wantAttempts int
+		wantStatus   int
+	}{
+		"success": {
+			policy:       testRetryPolicy(),
+			statusCodes:  []int{http.StatusOK},
+			wantAttempts: 1,
+			wantStatus:   http.StatusOK,
+		},
+		"5xx retried by default": {
+			policy:       testRetryPolicy(),
+			statusCodes:  []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 3,
+			wantStatus:   http.StatusOK,
+		},
+		"429 retried by default": {
+			policy:       testRetryPolicy(),
+			statusCodes:  []int{http.StatusTooManyRequests, http.StatusOK},
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+		"404 not retried": {
+			policy:       testRetryPolicy(),
+			statusCodes:  []int{http.StatusNotFound, http.StatusOK},
+			wantAttempts: 1,
+			wantStatus:   http.StatusNotFound,
+		},
+		"configured status codes": {
+			policy:       testRetryPolicy(http.StatusConflict),
+			statusCodes:  []int{http.StatusConflict, http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 2,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		"max attempts exceeded": {
+			policy:       testRetryPolicy(),
+			statusCodes:  []int{http.StatusInternalServerError},
+			wantAttempts: 3,
+			wantStatus:   http.StatusInternalServerError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int32
+
+			ctx := ContextWithRetryPolicy(context.Background(), test.policy)
+
+			_, resp, _ := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+				i := int(atomic.AddInt32(&attempts, 1)) - 1
+				if i >= len(test.statusCodes) {
+					i = len(test.statusCodes) - 1
+				}
+
+				return nil, &http.Response{StatusCode: test.statusCodes[i], Header: http.Header{}}, nil
+			})
+
+			require.Equal(t, test.wantAttempts, int(attempts))
+			require.Equal(t, test.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	var attempts int32
+
+	ctx := ContextWithRetryPolicy(context.Background(), testRetryPolicy())
+
+	start := time.Now()
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return nil, &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"1"}},
+			}, nil
+		}
+
+		return nil, &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryStopsWhenContextCanceled(t *testing.T) {
+	var attempts int32
+
+	policy := testRetryPolicy()
+	policy.InitialInterval = time.Hour
+	policy.MaxInterval = time.Hour
+
+	client := &gate.GatewayClient{Context: ContextWithRetryPolicy(context.Background(), policy)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	ctx = withClientContext(ctx, client)
+
+	_, _, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}, nil
+	})
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int32(1), attempts)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		header     string
+		want       time.Duration
+	}{
+		"seconds":          {http.StatusTooManyRequests, "5", 5 * time.Second},
+		"503":              {http.StatusServiceUnavailable, "2", 2 * time.Second},
+		"missing header":   {http.StatusTooManyRequests, "", 0},
+		"invalid header":   {http.StatusTooManyRequests, "soon", 0},
+		"date in the past": {http.StatusTooManyRequests, "Wed, 21 Oct 2015 07:28:00 GMT", 0},
+		"other status":     {http.StatusInternalServerError, "5", 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: test.statusCode, Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Retry-After", test.header)
+			}
+
+			require.Equal(t, test.want, parseRetryAfter(resp))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+const (
+	// DefaultTaskPollInterval is the default time to wait between two task
+	// status polls.
+	DefaultTaskPollInterval = 2 * time.Second
+
+	// maxTaskPollInterval caps the time to wait between two task status
+	// polls.
+	maxTaskPollInterval = 30 * time.Second
+)
+
+// Task status values reported by Orca.
+const (
+	TaskStatusSucceeded      = "SUCCEEDED"
+	TaskStatusStopped        = "STOPPED"
+	TaskStatusSkipped        = "SKIPPED"
+	TaskStatusTerminal       = "TERMINAL"
+	TaskStatusFailedContinue = "FAILED_CONTINUE"
+	TaskStatusCanceled       = "CANCELED"
+)
+
+// TaskResult is the outcome of an Orca task.
+type TaskResult struct {
+	// ID of the task.
+	ID string
+	// Status is the last status reported for the task.
+	Status string
+	// StageErrors contains the error messages of failed task stages.
+	StageErrors []string
+	// Outputs contains the merged outputs of all task stages.
+	Outputs map[string]interface{}
+}
+
+// Completed returns true if the task reached a final status.
+func (r *TaskResult) Completed() bool {
+	switch r.Status {
+	case TaskStatusSucceeded, TaskStatusStopped, TaskStatusSkipped,
+		TaskStatusTerminal, TaskStatusFailedContinue, TaskStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Succeeded returns true if the task completed successfully.
+func (r *TaskResult) Succeeded() bool {
+	switch r.Status {
+	case TaskStatusSucceeded, TaskStatusStopped, TaskStatusSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// TaskError is returned by SubmitTaskAndWait if a task completed without
+// succeeding.
+type TaskError struct {
+	Result *TaskResult
+}
+
+// Error implements the error interface.
+func (e *TaskError) Error() string {
+	if len(e.Result.StageErrors) == 0 {
+		return fmt.Sprintf("task %s finished with status %s", e.Result.ID, e.Result.Status)
+	}
+
+	return fmt.Sprintf("task %s finished with status %s: %s",
+		e.Result.ID, e.Result.Status, strings.Join(e.Result.StageErrors, "; "))
+}
+
+// SubmitTaskAndWait submits task to Orca and polls its status every
+// pollInterval until it completed, timeout is exceeded or ctx is canceled.
+// The poll interval is doubled after each unsuccessful poll up to a maximum
+// of 30 seconds. A *TaskError is returned if the task completed without
+// succeeding.
+func SubmitTaskAndWait(ctx context.Context, client *gate.GatewayClient, task map[string]interface{}, pollInterval, timeout time.Duration) (*TaskResult, error) {
+	ctx, cancel := context.WithTimeout(withClientContext(ctx, client), timeout)
+	defer cancel()
+
+	id, err := submitTask(ctx, client, task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit task: %w", err)
+	}
+
+	result, err := waitForTask(ctx, client, id, pollInterval)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Succeeded() {
+		return result, &TaskError{Result: result}
+	}
+
+	return result, nil
+}
+
+// submitTask submits task to Orca and returns the ID of the created task.
+func submitTask(ctx context.Context, client *gate.GatewayClient, task map[string]interface{}) (string, error) {
+	ref, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		return client.TaskControllerApi.TaskUsingPOST1(ctx, task)
+	})
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", errors.NewResponseError(resp, err)
+	}
+
+	rawRef, ok := ref["ref"].(string)
+	if !ok {
+		return "", fmt.Errorf("task response did not contain a ref: %v", ref)
+	}
+
+	toks := strings.Split(rawRef, "/")
+
+	return toks[len(toks)-1], nil
+}
+
+// waitForTask polls the task with id until it completed or ctx is done and
+// returns the last task result received.
+func waitForTask(ctx context.Context, client *gate.GatewayClient, id string, pollInterval time.Duration) (*TaskResult, error) {
+	result := &TaskResult{ID: id}
+
+	for {
+		task, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+			return client.TaskControllerApi.GetTaskUsingGET1(ctx, id)
+		})
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("stopped waiting for task %s to complete (last status: %q): %w", id, result.Status, ctx.Err())
+		}
+
+		if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return result, fmt.Errorf("failed to fetch status of task %s: %w", id, errors.NewResponseError(resp, err))
+		}
+
+		result = parseTaskResult(id, task)
+		if result.Completed() {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("stopped waiting for task %s to complete (last status: %q): %w", id, result.Status, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+
+		pollInterval *= 2
+		if pollInterval > maxTaskPollInterval {
+			pollInterval = maxTaskPollInterval
+		}
+	}
+}
+
+// parseTaskResult converts the raw task returned by Gate into a *TaskResult.
+// Stage errors are extracted from the exception details Orca records in the
+// stage context.
+func parseTaskResult(id string, task map[string]interface{}) *TaskResult {
+	result := &TaskResult{
+		ID:      id,
+		Outputs: make(map[string]interface{}),
+	}
+
+	result.Status, _ = task["status"].(string)
+
+	execution, _ := task["execution"].(map[string]interface{})
+	stages, _ := execution["stages"].([]interface{})
+
+	for _, s := range stages {
+		stage, _ := s.(map[string]interface{})
+
+		if outputs, ok := stage["outputs"].(map[string]interface{}); ok {
+			for k, v := range outputs {
+				result.Outputs[k] = v
+			}
+		}
+
+		stageContext, _ := stage["context"].(map[string]interface{})
+		exception, _ := stageContext["exception"].(map[string]interface{})
+		details, _ := exception["details"].(map[string]interface{})
+
+		if errs, ok := details["errors"].([]interface{}); ok && len(errs) > 0 {
+			for _, e := range errs {
+				result.StageErrors = append(result.StageErrors, fmt.Sprint(e))
+			}
+		} else if msg, ok := details["error"].(string); ok && msg != "" {
+			result.StageErrors = append(result.StageErrors, msg)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+	gateapi "github.com/spinnaker/spin/gateapi"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *gate.GatewayClient {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return &gate.GatewayClient{
+		APIClient: gateapi.NewAPIClient(&gateapi.Configuration{
+			BasePath:   srv.URL,
+			HTTPClient: srv.Client(),
+		}),
+		Context: context.Background(),
+	}
+}
+
+// taskHandler serves a single task with id "01ABC" that reports the given
+// statuses on subsequent polls. The last status is repeated indefinitely.
+func taskHandler(task map[string]interface{}, statuses ...string) http.Handler {
+	var polls int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ref": "/tasks/01ABC"})
+	})
+	mux.HandleFunc("/tasks/01ABC", func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&polls, 1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		resp := map[string]interface{}{"id": "01ABC", "status": statuses[i]}
+		for k, v := range task {
+			resp[k] = v
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	return mux
+}
+
+func TestSubmitTaskAndWait(t *testing.T) {
+	t.Run("waits for task to succeed", func(t *testing.T) {
+		task := map[string]interface{}{
+			"execution": map[string]interface{}{
+				"stages": []interface{}{
+					map[string]interface{}{"outputs": map[string]interface{}{"foo": "bar"}},
+				},
+			},
+		}
+		client := newTestClient(t, taskHandler(task, "NOT_STARTED", "RUNNING", TaskStatusSucceeded))
+
+		result, err := SubmitTaskAndWait(context.Background(), client, map[string]interface{}{}, time.Millisecond, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "01ABC", result.ID)
+		require.Equal(t, TaskStatusSucceeded, result.Status)
+		require.Equal(t, map[string]interface{}{"foo": "bar"}, result.Outputs)
+	})
+
+	t.Run("returns stage errors of failed tasks", func(t *testing.T) {
+		task := map[string]interface{}{
+			"execution": map[string]interface{}{
+				"stages": []interface{}{
+					map[string]interface{}{
+						"context": map[string]interface{}{
+							"exception": map[string]interface{}{
+								"details": map[string]interface{}{
+									"errors": []interface{}{"Application has 2 server groups", "Application has 1 load balancer"},
+								},
+							},
+						},
+					},
+					map[string]interface{}{
+						"context": map[string]interface{}{
+							"exception": map[string]interface{}{
+								"details": map[string]interface{}{
+									"error": "Unexpected Task Failure",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		client := newTestClient(t, taskHandler(task, TaskStatusTerminal))
+
+		result, err := SubmitTaskAndWait(context.Background(), client, map[string]interface{}{}, time.Millisecond, time.Minute)
+
+		var taskErr *TaskError
+		require.True(t, errors.As(err, &taskErr))
+		require.Equal(t, result, taskErr.Result)
+		require.EqualError(t, err, "task 01ABC finished with status TERMINAL: "+
+			"Application has 2 server groups; Application has 1 load balancer; Unexpected Task Failure")
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		client := newTestClient(t, taskHandler(nil, "RUNNING"))
+
+		result, err := SubmitTaskAndWait(context.Background(), client, map[string]interface{}{}, time.Millisecond, 20*time.Millisecond)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Equal(t, "RUNNING", result.Status)
+	})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+// GetVersion returns the version of Gate. It can be used to check whether
+// Gate is reachable with the credentials of the client.
+func GetVersion(ctx context.Context, client *gate.GatewayClient) (string, error) {
+	ctx = withClientContext(ctx, client)
+
+	var version string
+
+	_, resp, err := retry(ctx, func() (map[string]interface{}, *http.Response, error) {
+		v, resp, err := client.VersionControllerApi.GetVersionUsingGET(ctx)
+		version = v.Version
+		return nil, resp, err
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return "", errors.NewResponseError(resp, err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,140 @@
+package spinnaker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourceApplication() *schema.Resource {
+	s := datasourceSchemaFromResourceSchema(resourceApplication().Schema)
+
+	s["application"] = &schema.Schema{
+		Description: "Name of the application.",
+		Type:        schema.TypeString,
+		Required:    true,
+	}
+	s["accounts"] = &schema.Schema{
+		Description: "Accounts the application is deployed to.",
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+	s["clusters"] = &schema.Schema{
+		Description: "Clusters of the application, grouped by account.",
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"account": {
+					Description: "Name of the account.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"names": {
+					Description: "Names of the clusters in the account.",
+					Type:        schema.TypeList,
+					Computed:    true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+		Description: "Provides an application that is managed elsewhere.",
+		Schema:      s,
+		ReadContext: datasourceApplicationRead,
+	}
+}
+
+// datasourceSchemaFromResourceSchema returns a copy of the resource schema
+// with all attributes turned into computed attributes.
+func datasourceSchemaFromResourceSchema(rs map[string]*schema.Schema) map[string]*schema.Schema {
+	ds := make(map[string]*schema.Schema, len(rs))
+
+	for k, v := range rs {
+		s := &schema.Schema{
+			Type:        v.Type,
+			Description: v.Description,
+			Sensitive:   v.Sensitive,
+			Computed:    true,
+		}
+
+		switch elem := v.Elem.(type) {
+		case *schema.Resource:
+			s.Elem = &schema.Resource{Schema: datasourceSchemaFromResourceSchema(elem.Schema)}
+		case *schema.Schema:
+			s.Elem = &schema.Schema{Type: elem.Type}
+		}
+
+		ds[k] = s
+	}
+
+	return ds
+}
+
+func datasourceApplicationRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+
+	var app applicationRead
+
+	if err := api.GetApplication(ctx, client, applicationName, &app); err != nil {
+		return diag.Errorf("failed to fetch application %q: %s", applicationName, err)
+	}
+
+	if err := readApplication(data, app); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("accounts", splitCommaSeparated(app.Attributes.Accounts)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("clusters", flattenClusters(app.Clusters)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func flattenClusters(clusters map[string][]clusterSummary) []interface{} {
+	accounts := make([]string, 0, len(clusters))
+	for account := range clusters {
+		accounts = append(accounts, account)
+	}
+
+	sort.Strings(accounts)
+
+	result := make([]interface{}, 0, len(accounts))
+
+	for _, account := range accounts {
+		names := make([]string, 0, len(clusters[account]))
+		for _, cluster := range clusters[account] {
+			names = append(names, cluster.Name)
+		}
+
+		sort.Strings(names)
+
+		result = append(result, map[string]interface{}{
+			"account": account,
+			"names":   names,
+		})
+	}
+
+	return result
+}
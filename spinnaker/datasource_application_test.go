@@ -0,0 +1,49 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourceApplicationRead(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutApplication("myapp", map[string]interface{}{
+		"email":          "team@example.com",
+		"repoType":       "github",
+		"repoSlug":       "myapp",
+		"cloudProviders": "kubernetes",
+		"accounts":       "staging,prod",
+		"instancePort":   float64(8080),
+		"permissions": map[string]interface{}{
+			"READ":    []interface{}{"team"},
+			"EXECUTE": []interface{}{"team"},
+		},
+	})
+	srv.PutCluster("myapp", "prod", "myapp-web")
+	srv.PutCluster("myapp", "prod", "myapp-api")
+	srv.PutCluster("myapp", "staging", "myapp-web")
+
+	d := datasourceApplication()
+	meta := newTestClientConfig(srv)
+
+	data := schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"application": "myapp"})
+	require.False(t, d.ReadContext(context.Background(), data, meta).HasError())
+
+	require.Equal(t, "myapp", data.Id())
+	require.Equal(t, "team@example.com", data.Get("email"))
+	require.Equal(t, "github", data.Get("repo_type"))
+	require.Equal(t, 8080, data.Get("instance_port"))
+	require.Equal(t, []interface{}{"kubernetes"}, data.Get("cloud_providers"))
+	require.Equal(t, []interface{}{"staging", "prod"}, data.Get("accounts"))
+	require.Equal(t, []interface{}{"team"}, data.Get("permissions.0.execute").(*schema.Set).List())
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"account": "prod", "names": []interface{}{"myapp-api", "myapp-web"}},
+		map[string]interface{}{"account": "staging", "names": []interface{}{"myapp-web"}},
+	}, data.Get("clusters"))
+
+	data = schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"application": "unknown"})
+	require.True(t, d.ReadContext(context.Background(), data, meta).HasError())
+}
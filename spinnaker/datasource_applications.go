@@ -0,0 +1,195 @@
+package spinnaker
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourceApplications() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides the applications known to Spinnaker, optionally filtered.",
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Description:  "Regular expression the application name must match.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"email": {
+				Description: "Email of the application owner.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"cloud_provider": {
+				Description: "Cloud provider the application must be configured for, e.g. `kubernetes`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"account": {
+				Description: "Account the application must be deployed to.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"names": {
+				Description: "Names of the applications found, sorted alphabetically.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"applications": {
+				Description: "Applications found, sorted by name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the application.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"email": {
+							Description: "Email of the application owner.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"cloud_providers": {
+							Description: "Cloud providers the application is configured for.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"permissions": {
+							Description: "Roles granted access to the application.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"read": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"write": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"execute": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+								},
+							},
+						},
+						"create_ts": {
+							Description: "Creation time of the application in milliseconds since the epoch.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		ReadContext: datasourceApplicationsRead,
+	}
+}
+
+type applicationListItem struct {
+	Name           string
+	Email          string
+	CloudProviders interface{}
+	Permissions    *permissions
+	CreateTs       interface{}
+}
+
+func datasourceApplicationsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	nameRegex := data.Get("name_regex").(string)
+	email := data.Get("email").(string)
+	cloudProvider := data.Get("cloud_provider").(string)
+	account := data.Get("account").(string)
+
+	// The regular expression has been validated by the schema already.
+	nameRe := regexp.MustCompile(nameRegex)
+
+	var apps []applicationListItem
+
+	if err := api.ListApplications(ctx, client, account, email, &apps); err != nil {
+		return diag.Errorf("failed to list applications: %s", err)
+	}
+
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+
+	names := make([]string, 0, len(apps))
+	applications := make([]interface{}, 0, len(apps))
+
+	for _, app := range apps {
+		cloudProviders := splitCommaSeparated(app.CloudProviders)
+
+		if !nameRe.MatchString(app.Name) {
+			continue
+		}
+
+		if cloudProvider != "" && !containsString(cloudProviders, cloudProvider) {
+			continue
+		}
+
+		names = append(names, app.Name)
+		applications = append(applications, map[string]interface{}{
+			"name":            app.Name,
+			"email":           app.Email,
+			"cloud_providers": cloudProviders,
+			"permissions":     flattenPermissions(app.Permissions),
+			"create_ts":       stringValue(app.CreateTs),
+		})
+	}
+
+	if err := data.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("applications", applications); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(strconv.Itoa(schema.HashString(strings.Join([]string{nameRegex, email, cloudProvider, account}, "\n"))))
+
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
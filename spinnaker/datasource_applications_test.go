@@ -0,0 +1,94 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourceApplicationsRead(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutApplication("payments", map[string]interface{}{
+		"email":          "payments@example.com",
+		"cloudProviders": "kubernetes,aws",
+		"accounts":       "prod,staging",
+		"createTs":       "1700000000000",
+		"permissions": map[string]interface{}{
+			"READ":  []interface{}{"payments", "ops"},
+			"WRITE": []interface{}{"payments"},
+		},
+	})
+	srv.PutApplication("payouts", map[string]interface{}{
+		"email":          "payments@example.com",
+		"cloudProviders": "aws",
+		"accounts":       "staging",
+	})
+	srv.PutApplication("search", map[string]interface{}{
+		"email":          "search@example.com",
+		"cloudProviders": "kubernetes",
+		"accounts":       "prod",
+	})
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected []interface{}
+	}{
+		{
+			name:     "no filters",
+			raw:      map[string]interface{}{},
+			expected: []interface{}{"payments", "payouts", "search"},
+		},
+		{
+			name:     "name regex",
+			raw:      map[string]interface{}{"name_regex": "^pay"},
+			expected: []interface{}{"payments", "payouts"},
+		},
+		{
+			name:     "email",
+			raw:      map[string]interface{}{"email": "search@example.com"},
+			expected: []interface{}{"search"},
+		},
+		{
+			name:     "cloud provider",
+			raw:      map[string]interface{}{"cloud_provider": "kubernetes"},
+			expected: []interface{}{"payments", "search"},
+		},
+		{
+			name:     "account",
+			raw:      map[string]interface{}{"account": "staging", "cloud_provider": "aws"},
+			expected: []interface{}{"payments", "payouts"},
+		},
+	}
+
+	d := datasourceApplications()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := schema.TestResourceDataRaw(t, d.Schema, tt.raw)
+			require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+			require.NotEmpty(t, data.Id())
+			require.Equal(t, tt.expected, data.Get("names"))
+		})
+	}
+
+	data := schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"name_regex": "^payments$"})
+	require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":            "payments",
+			"email":           "payments@example.com",
+			"cloud_providers": []interface{}{"kubernetes", "aws"},
+			"permissions": []interface{}{
+				map[string]interface{}{
+					"read":    []interface{}{"payments", "ops"},
+					"write":   []interface{}{"payments"},
+					"execute": []interface{}{},
+				},
+			},
+			"create_ts": "1700000000000",
+		},
+	}, data.Get("applications"))
+}
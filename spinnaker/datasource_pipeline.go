@@ -1,29 +1,145 @@
 package spinnaker
 
-import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+import (
+	"context"
+	"fmt"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
 
 func datasourcePipeline() *schema.Resource {
+	structured := datasourceSchemaFromResourceSchema(resourceStructuredPipeline().Schema)
+
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"application": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"name"},
 			},
 			"name": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"application"},
+				ExactlyOneOf: []string{"name", "pipeline_id"},
 			},
 			"pipeline": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 			"pipeline_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "pipeline_id"},
+			},
+			"last_modified_by": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"update_ts": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"stages":             structured["stage"],
+			"triggers":           structured["trigger"],
+			"parameters":         structured["parameter"],
+			"expected_artifacts": structured["expected_artifact"],
 		},
-		Read: resourcePipelineRead,
+		ReadContext: datasourcePipelineRead,
 	}
 }
+
+// datasourcePipelineRead reads the pipeline either by its application and
+// name or, if pipeline_id is set, by its ID. Unlike the resource, it fails if
+// the pipeline does not exist.
+func datasourcePipelineRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var p pipelineRead
+
+	pipeline, err := fetchDatasourcePipeline(ctx, client, data, &p)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	blocks := []struct {
+		key     string
+		flatten func(map[string]interface{}) ([]interface{}, error)
+	}{
+		{key: "stages", flatten: flattenPipelineStages},
+		{key: "triggers", flatten: flattenPipelineTriggers},
+		{key: "parameters", flatten: flattenPipelineParameters},
+		{key: "expected_artifacts", flatten: flattenPipelineExpectedArtifacts},
+	}
+
+	for _, block := range blocks {
+		values, err := block.flatten(pipeline)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := data.Set(block.key, values); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	lastModifiedBy := stringValue(pipeline["lastModifiedBy"])
+	updateTs := stringValue(pipeline["updateTs"])
+
+	encodedPipeline, err := editAndEncodePipeline(pipeline)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	attributes := map[string]interface{}{
+		"application":      p.Application,
+		"name":             p.Name,
+		"pipeline":         encodedPipeline,
+		"pipeline_id":      p.ID,
+		"last_modified_by": lastModifiedBy,
+		"update_ts":        updateTs,
+	}
+
+	for key, value := range attributes {
+		if err := data.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	data.SetId(p.ID)
+
+	return nil
+}
+
+func fetchDatasourcePipeline(ctx context.Context, client *gate.GatewayClient, data *schema.ResourceData, dest *pipelineRead) (map[string]interface{}, error) {
+	if pipelineID, ok := data.GetOk("pipeline_id"); ok {
+		pipeline, err := getPipelineByID(ctx, client, pipelineID.(string), dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pipeline with id %q: %w", pipelineID, err)
+		}
+
+		return pipeline, nil
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+
+	pipeline, err := api.GetPipeline(ctx, client, applicationName, pipelineName, dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pipeline %q for application %q: %w",
+			pipelineName, applicationName, err)
+	}
+
+	return pipeline, nil
+}
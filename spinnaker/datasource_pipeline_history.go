@@ -0,0 +1,141 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourcePipelineHistory() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides the revision history Front50 keeps for a pipeline config.",
+		Schema: map[string]*schema.Schema{
+			"application": {
+				Description:  "Name of the application the pipeline belongs to.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"name"},
+			},
+			"name": {
+				Description:  "Name of the pipeline.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"application"},
+				ExactlyOneOf: []string{"name", "pipeline_id"},
+			},
+			"pipeline_id": {
+				Description:  "ID of the pipeline.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "pipeline_id"},
+			},
+			"limit": {
+				Description:  "Maximum number of revisions to return.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      20,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"revisions": {
+				Description: "Revisions of the pipeline config, newest first.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"update_ts": {
+							Description: "Time of the revision in milliseconds since the epoch.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"last_modified_by": {
+							Description: "User who saved the revision.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"pipeline": {
+							Description: "Pipeline config of the revision as JSON.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		ReadContext: datasourcePipelineHistoryRead,
+	}
+}
+
+func datasourcePipelineHistoryRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipelineID := data.Get("pipeline_id").(string)
+
+	if pipelineID == "" {
+		applicationName := data.Get("application").(string)
+		pipelineName := data.Get("name").(string)
+
+		var p pipelineRead
+
+		if _, err := api.GetPipeline(ctx, client, applicationName, pipelineName, &p); err != nil {
+			return diag.Errorf("failed to fetch pipeline %q for application %q: %s",
+				pipelineName, applicationName, err)
+		}
+
+		pipelineID = p.ID
+	}
+
+	history, err := api.GetPipelineHistory(ctx, client, pipelineID, data.Get("limit").(int), nil)
+	if err != nil {
+		return diag.Errorf("failed to fetch history of pipeline with id %q: %s", pipelineID, err)
+	}
+
+	revisions := make([]interface{}, 0, len(history))
+
+	for _, revision := range history {
+		encoded, err := json.Marshal(revision)
+		if err != nil {
+			return diag.Errorf("failed to marshal pipeline revision: %s", err)
+		}
+
+		revisions = append(revisions, map[string]interface{}{
+			"update_ts":        stringValue(revision["updateTs"]),
+			"last_modified_by": stringValue(revision["lastModifiedBy"]),
+			"pipeline":         string(encoded),
+		})
+	}
+
+	// The latest revision reflects renames and moves to other applications.
+	if len(history) > 0 {
+		if err := data.Set("application", stringValue(history[0]["application"])); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := data.Set("name", stringValue(history[0]["name"])); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := data.Set("pipeline_id", pipelineID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("revisions", revisions); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(pipelineID)
+
+	return nil
+}
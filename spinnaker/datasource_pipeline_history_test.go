@@ -0,0 +1,37 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourcePipelineHistoryRead(t *testing.T) {
+	srv := newTestFakeGate(t)
+	id := srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "deploy"})
+
+	for _, user := range []string{"terraform", "someone"} {
+		pipeline, _ := srv.Pipeline("myapp", "deploy")
+		pipeline["lastModifiedBy"] = user
+		srv.PutPipeline(pipeline)
+	}
+
+	d := datasourcePipelineHistory()
+
+	for _, raw := range []map[string]interface{}{
+		{"application": "myapp", "name": "deploy", "limit": 2},
+		{"pipeline_id": id, "limit": 2},
+	} {
+		data := schema.TestResourceDataRaw(t, d.Schema, raw)
+		require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+		require.Equal(t, id, data.Id())
+		require.Equal(t, "deploy", data.Get("name"))
+		require.Equal(t, 2, data.Get("revisions.#"))
+		require.Equal(t, "someone", data.Get("revisions.0.last_modified_by"))
+		require.Equal(t, "terraform", data.Get("revisions.1.last_modified_by"))
+		require.NotEmpty(t, data.Get("revisions.0.update_ts"))
+		require.Contains(t, data.Get("revisions.0.pipeline"), `"lastModifiedBy":"someone"`)
+	}
+}
@@ -0,0 +1,179 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourcePipelineTemplateV2() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides a published version of a V2 pipeline template.",
+		Schema: map[string]*schema.Schema{
+			"template_id": {
+				Description: "ID of the template.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"tag": {
+				Description:   "Tag of the version to read. Defaults to the latest version.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"digest"},
+			},
+			"digest": {
+				Description:   "Digest of the version to read.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"tag"},
+			},
+			"template": {
+				Description: "JSON schema of the V2 pipeline template.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"variables": {
+				Description: "Variables declared by the template.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the variable.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type": {
+							Description: "Type of the variable, e.g. `string` or `int`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"default": {
+							Description: "Default value of the variable as JSON. Empty if the variable has no default.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"description": {
+							Description: "Description of the variable.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"versions": {
+				Description: "All published versions of the template.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": {
+							Description: "Tag of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"digest": {
+							Description: "Digest of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		ReadContext: datasourcePipelineTemplateV2Read,
+	}
+}
+
+func datasourcePipelineTemplateV2Read(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	templateID := data.Get("template_id").(string)
+	tag := data.Get("tag").(string)
+	digest := data.Get("digest").(string)
+
+	template, err := api.GetPipelineTemplateV2(ctx, client, templateID, tag, digest)
+	if err != nil {
+		return diag.Errorf("failed to fetch pipeline template %q: %s", templateID, err)
+	}
+
+	versionMap, err := api.ListPipelineTemplateV2Versions(ctx, client)
+	if err != nil {
+		return diag.Errorf("failed to list pipeline template versions: %s", err)
+	}
+
+	versions := versionMap[templateID]
+
+	if digest == "" {
+		digest = pipelineTemplateV2Digest(versions, tag)
+	}
+
+	variables, err := flattenPipelineTemplateV2Variables(template.Variables)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Unset template ID before marshalling so the template can be used as
+	// the template of a spinnaker_pipeline_template_v2 resource.
+	template.ID = ""
+
+	rawTemplate, err := json.Marshal(template)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	attributes := map[string]interface{}{
+		"digest":    digest,
+		"template":  string(rawTemplate),
+		"variables": variables,
+		"versions":  flattenPipelineTemplateV2Versions(versions),
+	}
+
+	for key, value := range attributes {
+		if err := data.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	data.SetId(pipelineTemplateV2ID(templateID, tag))
+
+	return nil
+}
+
+func flattenPipelineTemplateV2Variables(variables []api.PipelineTemplateV2Variable) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(variables))
+	for _, variable := range variables {
+		defaultValue := ""
+		if variable.DefaultValue != nil {
+			b, err := json.Marshal(variable.DefaultValue)
+			if err != nil {
+				return nil, err
+			}
+
+			defaultValue = string(b)
+		}
+
+		description := ""
+		if variable.Description != nil {
+			description = *variable.Description
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":        variable.Name,
+			"type":        variable.Type,
+			"default":     defaultValue,
+			"description": description,
+		})
+	}
+
+	return result, nil
+}
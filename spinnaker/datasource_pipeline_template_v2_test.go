@@ -0,0 +1,53 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourcePipelineTemplateV2Read(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipelineTemplateV2()
+
+	for _, tag := range []string{"stable", "unstable"} {
+		data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"template_id": "my-template",
+			"tag":         tag,
+			"template": `{"schema":"v2","pipeline":{"stages":[]},"metadata":{"name":"` + tag + `","description":"baz","scopes":["global"]},` +
+				`"variables":[{"name":"replicas","type":"int","defaultValue":2,"description":"Number of replicas"},{"name":"image","type":"string"}]}`,
+		})
+		require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	}
+
+	stable, _ := srv.PipelineTemplateV2("my-template", "stable")
+
+	d := datasourcePipelineTemplateV2()
+
+	for _, raw := range []map[string]interface{}{
+		{"template_id": "my-template", "tag": "stable"},
+		{"template_id": "my-template", "digest": stable["digest"]},
+	} {
+		data := schema.TestResourceDataRaw(t, d.Schema, raw)
+		require.False(t, d.ReadContext(ctx, data, meta).HasError())
+		require.Equal(t, stable["digest"], data.Get("digest"))
+		require.Contains(t, data.Get("template"), `"name":"stable"`)
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"name": "replicas", "type": "int", "default": "2", "description": "Number of replicas"},
+			map[string]interface{}{"name": "image", "type": "string", "default": "", "description": ""},
+		}, data.Get("variables"))
+		require.Equal(t, 3, data.Get("versions.#"))
+	}
+
+	data := schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"template_id": "my-template"})
+	require.False(t, d.ReadContext(ctx, data, meta).HasError())
+	require.Equal(t, "my-template", data.Id())
+	require.Contains(t, data.Get("template"), `"name":"unstable"`)
+
+	data = schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"template_id": "my-template", "tag": "canary"})
+	require.True(t, d.ReadContext(ctx, data, meta).HasError())
+}
@@ -0,0 +1,88 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourcePipelineRead(t *testing.T) {
+	srv := newTestFakeGate(t)
+	id := srv.PutPipeline(map[string]interface{}{
+		"application":          "myapp",
+		"name":                 "deploy",
+		"keepWaitingPipelines": true,
+	})
+	srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "rollback"})
+
+	d := datasourcePipeline()
+
+	for _, raw := range []map[string]interface{}{
+		{"application": "myapp", "name": "deploy"},
+		{"pipeline_id": id},
+	} {
+		data := schema.TestResourceDataRaw(t, d.Schema, raw)
+		require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+		require.Equal(t, id, data.Id())
+		require.Equal(t, "myapp", data.Get("application"))
+		require.Equal(t, "deploy", data.Get("name"))
+		require.Equal(t, `{"keepWaitingPipelines":true}`, data.Get("pipeline"))
+	}
+}
+
+func TestDatasourcePipelineReadParsesPipeline(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutPipeline(map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"stages": []interface{}{
+			map[string]interface{}{"refId": "1", "type": "wait", "name": "Wait", "waitTime": float64(30)},
+			map[string]interface{}{"refId": "2", "type": "manualJudgment", "name": "Approve", "requisiteStageRefIds": []interface{}{"1"}},
+		},
+		"triggers": []interface{}{
+			map[string]interface{}{"type": "cron", "enabled": true, "cronExpression": "0 0 * * * ?"},
+		},
+		"parameterConfig": []interface{}{
+			map[string]interface{}{"name": "version", "default": "latest", "required": true},
+		},
+		"expectedArtifacts": []interface{}{
+			map[string]interface{}{"id": "image", "displayName": "Image"},
+		},
+	})
+
+	d := datasourcePipeline()
+	data := schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"application": "myapp", "name": "deploy"})
+	require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+
+	require.Equal(t, 2, data.Get("stages.#"))
+	require.Equal(t, "manualJudgment", data.Get("stages.1.type"))
+	require.Equal(t, "Approve", data.Get("stages.1.name"))
+	require.Equal(t, "2", data.Get("stages.1.ref_id"))
+	require.Equal(t, []interface{}{"1"}, data.Get("stages.1.requisite_stage_ref_ids"))
+	require.Equal(t, `{"waitTime":30}`, data.Get("stages.0.config"))
+	require.Equal(t, "cron", data.Get("triggers.0.type"))
+	require.Equal(t, true, data.Get("triggers.0.enabled"))
+	require.Equal(t, "version", data.Get("parameters.0.name"))
+	require.Equal(t, true, data.Get("parameters.0.required"))
+	require.Equal(t, "Image", data.Get("expected_artifacts.0.display_name"))
+	require.Equal(t, "anonymous", data.Get("last_modified_by"))
+}
+
+func TestDatasourcePipelineReadMissing(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "deploy"})
+
+	d := datasourcePipeline()
+
+	for _, raw := range []map[string]interface{}{
+		{"application": "myapp", "name": "dpeloy"},
+		{"pipeline_id": "does-not-exist"},
+	} {
+		data := schema.TestResourceDataRaw(t, d.Schema, raw)
+		diags := d.ReadContext(context.Background(), data, newTestClientConfig(srv))
+		require.True(t, diags.HasError())
+		require.Contains(t, diags[0].Summary, "failed to fetch pipeline")
+	}
+}
@@ -0,0 +1,181 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourcePipelines() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides all pipelines of an application, optionally filtered.",
+		Schema: map[string]*schema.Schema{
+			"application": {
+				Description: "Name of the application.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"name_regex": {
+				Description:  "Regular expression the pipeline name must match.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"include_disabled": {
+				Description: "Whether to include disabled pipelines.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"names": {
+				Description: "Names of the pipelines found, sorted alphabetically.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"pipelines": {
+				Description: "Pipelines found, sorted by name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the pipeline.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"pipeline_id": {
+							Description: "ID of the pipeline.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"disabled": {
+							Description: "Whether the pipeline is disabled.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"triggers": {
+							Description: "Triggers of the pipeline.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Description: "Type of the trigger.",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									"enabled": {
+										Description: "Whether the trigger is enabled.",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+								},
+							},
+						},
+						"pipeline": {
+							Description: "Pipeline config as JSON.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		ReadContext: datasourcePipelinesRead,
+	}
+}
+
+type pipelineListItem struct {
+	Name     string
+	ID       string
+	Disabled bool
+	Triggers []struct {
+		Type    string
+		Enabled bool
+	}
+}
+
+func datasourcePipelinesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	includeDisabled := data.Get("include_disabled").(bool)
+
+	// The regular expression has been validated by the schema already.
+	nameRe := regexp.MustCompile(data.Get("name_regex").(string))
+
+	var items []pipelineListItem
+
+	payload, err := api.ListPipelines(ctx, client, applicationName, &items)
+	if err != nil {
+		return diag.Errorf("failed to list pipelines of application %q: %s", applicationName, err)
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return items[order[i]].Name < items[order[j]].Name
+	})
+
+	names := make([]string, 0, len(items))
+	pipelines := make([]interface{}, 0, len(items))
+
+	for _, i := range order {
+		item := items[i]
+
+		if !nameRe.MatchString(item.Name) || (item.Disabled && !includeDisabled) {
+			continue
+		}
+
+		encoded, err := json.Marshal(payload[i])
+		if err != nil {
+			return diag.Errorf("failed to marshal pipeline %q: %s", item.Name, err)
+		}
+
+		triggers := make([]interface{}, 0, len(item.Triggers))
+		for _, trigger := range item.Triggers {
+			triggers = append(triggers, map[string]interface{}{
+				"type":    trigger.Type,
+				"enabled": trigger.Enabled,
+			})
+		}
+
+		names = append(names, item.Name)
+		pipelines = append(pipelines, map[string]interface{}{
+			"name":        item.Name,
+			"pipeline_id": item.ID,
+			"disabled":    item.Disabled,
+			"triggers":    triggers,
+			"pipeline":    string(encoded),
+		})
+	}
+
+	if err := data.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("pipelines", pipelines); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(applicationName)
+
+	return nil
+}
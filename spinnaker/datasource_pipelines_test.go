@@ -0,0 +1,71 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourcePipelinesRead(t *testing.T) {
+	srv := newTestFakeGate(t)
+	deployID := srv.PutPipeline(map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy-prod",
+		"triggers": []interface{}{
+			map[string]interface{}{"type": "docker", "enabled": true, "repository": "myorg/myapp"},
+			map[string]interface{}{"type": "cron", "enabled": false},
+		},
+	})
+	srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "deploy-staging", "disabled": true})
+	srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "promote"})
+	srv.PutPipeline(map[string]interface{}{"application": "otherapp", "name": "deploy-prod"})
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected []interface{}
+	}{
+		{
+			name:     "all",
+			raw:      map[string]interface{}{"application": "myapp"},
+			expected: []interface{}{"deploy-prod", "deploy-staging", "promote"},
+		},
+		{
+			name:     "name regex",
+			raw:      map[string]interface{}{"application": "myapp", "name_regex": "^deploy-"},
+			expected: []interface{}{"deploy-prod", "deploy-staging"},
+		},
+		{
+			name:     "exclude disabled",
+			raw:      map[string]interface{}{"application": "myapp", "include_disabled": false},
+			expected: []interface{}{"deploy-prod", "promote"},
+		},
+	}
+
+	d := datasourcePipelines()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := schema.TestResourceDataRaw(t, d.Schema, tt.raw)
+			require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+			require.Equal(t, tt.expected, data.Get("names"))
+		})
+	}
+
+	data := schema.TestResourceDataRaw(t, d.Schema, map[string]interface{}{"application": "myapp"})
+	require.False(t, d.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+	require.Equal(t, deployID, data.Get("pipelines.0.pipeline_id"))
+	require.Equal(t, false, data.Get("pipelines.0.disabled"))
+	require.Equal(t, true, data.Get("pipelines.1.disabled"))
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"type": "docker", "enabled": true},
+		map[string]interface{}{"type": "cron", "enabled": false},
+	}, data.Get("pipelines.0.triggers"))
+
+	var pipeline map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data.Get("pipelines.0.pipeline").(string)), &pipeline))
+	require.Equal(t, "myorg/myapp", pipeline["triggers"].([]interface{})[0].(map[string]interface{})["repository"])
+}
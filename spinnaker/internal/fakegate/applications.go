@@ -0,0 +1,210 @@
+package fakegate
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Application returns the attributes of the application with name and
+// whether it exists.
+func (s *Server) Application(name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.applications[strings.ToLower(name)]
+
+	return copyMap(app), ok
+}
+
+// PutApplication creates or replaces the application with name using the
+// given attributes, bypassing the task API.
+func (s *Server) PutApplication(name string, attributes map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app := copyMap(attributes)
+	if app == nil {
+		app = make(map[string]interface{})
+	}
+
+	app["name"] = strings.ToLower(name)
+
+	s.applications[strings.ToLower(name)] = app
+}
+
+// PutCluster adds a cluster with name in account to the clusters reported for
+// application.
+func (s *Server) PutCluster(application, account, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(application)
+
+	if s.clusters[key] == nil {
+		s.clusters[key] = make(map[string][]string)
+	}
+
+	s.clusters[key][account] = append(s.clusters[key][account], name)
+}
+
+// saveApplication creates or updates an application from the application
+// attributes of a createApplication or updateApplication job. Must be called
+// with s.mu held.
+func (s *Server) saveApplication(create bool, attributes map[string]interface{}) error {
+	name, _ := attributes["name"].(string)
+	if name == "" {
+		return fmt.Errorf("application name must not be empty")
+	}
+
+	key := strings.ToLower(name)
+
+	app, exists := s.applications[key]
+
+	switch {
+	case create && exists:
+		return fmt.Errorf("application %s already exists", key)
+	case !create && !exists:
+		return fmt.Errorf("application %s does not exist", key)
+	case create:
+		app = map[string]interface{}{"createTs": timestamp()}
+	}
+
+	for k, v := range copyMap(attributes) {
+		app[k] = v
+	}
+
+	app["name"] = key
+	app["updateTs"] = timestamp()
+
+	s.applications[key] = app
+
+	return nil
+}
+
+// deleteApplication deletes the application with name along with its
+// pipelines. Must be called with s.mu held.
+func (s *Server) deleteApplication(name string) error {
+	key := strings.ToLower(name)
+
+	if _, ok := s.applications[key]; !ok {
+		return fmt.Errorf("application %s does not exist", key)
+	}
+
+	delete(s.applications, key)
+	delete(s.clusters, key)
+
+	for id, revisions := range s.pipelines {
+		if strings.EqualFold(revisions[0]["application"].(string), key) {
+			delete(s.pipelines, id)
+		}
+	}
+
+	return nil
+}
+
+// serveApplications handles:
+//
+//	GET /applications?account={account}&owner={email}
+//	GET /applications/{application}
+//	GET /applications/{application}/pipelineConfigs
+//	GET /applications/{application}/pipelineConfigs/{pipelineName}
+func (s *Server) serveApplications(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	switch len(segments) {
+	case 0:
+		s.listApplications(w, r.URL.Query().Get("account"), r.URL.Query().Get("owner"))
+	case 1:
+		s.getApplication(w, segments[0])
+	case 2:
+		if segments[1] != "pipelineConfigs" {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		s.listPipelineConfigs(w, segments[0])
+	case 3:
+		if segments[1] != "pipelineConfigs" {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		s.getPipelineConfig(w, segments[0], segments[2])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// listApplications lists all applications, optionally restricted to those
+// with account in their comma separated accounts attribute and those with
+// owner as email.
+func (s *Server) listApplications(w http.ResponseWriter, account, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.applications))
+	for name, app := range s.applications {
+		if owner != "" && !strings.EqualFold(fmt.Sprint(app["email"]), owner) {
+			continue
+		}
+
+		if account != "" && !containsAccount(app["accounts"], account) {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	apps := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		apps = append(apps, copyMap(s.applications[name]))
+	}
+
+	writeJSON(w, http.StatusOK, apps)
+}
+
+func (s *Server) getApplication(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.applications[strings.ToLower(name)]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Application '%s' not found", name))
+		return
+	}
+
+	clusters := make(map[string]interface{})
+	for account, names := range s.clusters[strings.ToLower(name)] {
+		accountClusters := make([]interface{}, 0, len(names))
+		for _, clusterName := range names {
+			accountClusters = append(accountClusters, map[string]interface{}{"name": clusterName})
+		}
+
+		clusters[account] = accountClusters
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"name":       app["name"],
+		"attributes": copyMap(app),
+		"clusters":   clusters,
+	})
+}
+
+func containsAccount(accounts interface{}, account string) bool {
+	value, _ := accounts.(string)
+
+	for _, a := range strings.Split(value, ",") {
+		if strings.TrimSpace(a) == account {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,278 @@
+// Package fakegate implements an in-memory fake of the Spinnaker Gate API that
+// covers the endpoints used by the provider. It allows exercising resources
+// end-to-end in unit tests without a running Spinnaker installation.
+package fakegate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault describes an error the server injects into responses to matching
+// requests.
+type Fault struct {
+	// Method restricts the fault to requests with the given HTTP method. The
+	// fault matches all methods if empty.
+	Method string
+	// Path restricts the fault to requests whose URL path starts with the
+	// given prefix. The fault matches all paths if empty.
+	Path string
+	// StatusCode is the status code to respond with. Ignored if EOF is set.
+	StatusCode int
+	// RetryAfter is sent as Retry-After header along with StatusCode if set.
+	RetryAfter string
+	// EOF closes the connection without sending a response.
+	EOF bool
+	// Delay is the time to wait before handling the request. If neither
+	// StatusCode nor EOF is set, the request is handled normally after the
+	// delay.
+	Delay time.Duration
+	// Times is the number of matching requests the fault is applied to. The
+	// fault is applied to all matching requests if zero.
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+		return false
+	}
+
+	return strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// Server is a fake Gate server backed by in-memory state. All methods are
+// safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	applications map[string]map[string]interface{}
+	clusters     map[string]map[string][]string
+	projects     map[string]map[string]interface{}
+	pipelines    map[string][]map[string]interface{}
+	templates    map[string]map[string]interface{}
+	templatesV2  map[string]map[string]map[string]interface{}
+	tasks        map[string]*task
+	jobs         []map[string]interface{}
+
+	faults       []*Fault
+	taskPolls    int
+	taskFailures map[string]string
+	user         string
+
+	nextID int
+}
+
+// NewServer starts a new fake Gate server. The caller must call Close when
+// finished.
+func NewServer() *Server {
+	s := &Server{
+		applications: make(map[string]map[string]interface{}),
+		clusters:     make(map[string]map[string][]string),
+		projects:     make(map[string]map[string]interface{}),
+		pipelines:    make(map[string][]map[string]interface{}),
+		templates:    make(map[string]map[string]interface{}),
+		templatesV2:  make(map[string]map[string]map[string]interface{}),
+		tasks:        make(map[string]*task),
+		taskFailures: make(map[string]string),
+		user:         "anonymous",
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// InjectFault registers a fault that is applied to matching requests. Faults
+// are evaluated in the order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// SetUser sets the name of the user requests are authenticated as. Defaults
+// to "anonymous".
+func (s *Server) SetUser(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.user = name
+}
+
+// SetTaskPolls sets the number of times the status of a submitted task is
+// reported as RUNNING before the task completes. Defaults to 0, which
+// completes tasks on the first poll.
+func (s *Server) SetTaskPolls(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.taskPolls = n
+}
+
+// FailTasks makes all tasks containing a job of taskType fail with status
+// TERMINAL and the given error message.
+func (s *Server) FailTasks(taskType, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.taskFailures[taskType] = message
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if fault := s.matchFault(r); fault != nil {
+		if fault.Delay > 0 {
+			time.Sleep(fault.Delay)
+		}
+
+		if fault.EOF {
+			closeConnection(w)
+			return
+		}
+
+		if fault.StatusCode != 0 {
+			if fault.RetryAfter != "" {
+				w.Header().Set("Retry-After", fault.RetryAfter)
+			}
+
+			writeError(w, fault.StatusCode, "injected fault")
+			return
+		}
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch segments[0] {
+	case "version":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": "fake"})
+	case "auth":
+		s.mu.Lock()
+		user := s.user
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"username": user})
+	case "tasks":
+		s.serveTasks(w, r, segments[1:])
+	case "applications":
+		s.serveApplications(w, r, segments[1:])
+	case "pipelines":
+		s.servePipelines(w, r, segments[1:])
+	case "pipelineConfigs":
+		s.servePipelineConfigs(w, r, segments[1:])
+	case "projects":
+		s.serveProjects(w, r, segments[1:])
+	case "pipelineTemplates":
+		s.servePipelineTemplates(w, r, segments[1:])
+	case "v2":
+		if len(segments) > 1 && segments[1] == "pipelineTemplates" {
+			s.servePipelineTemplatesV2(w, r, segments[2:])
+			return
+		}
+
+		writeError(w, http.StatusNotFound, "not found")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) matchFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+// newID returns a new unique ID in the format of a UUID.
+func (s *Server) newID() string {
+	s.nextID++
+
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID)
+}
+
+func closeConnection(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		panic("fakegate: response writer does not support hijacking")
+	}
+
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		panic(err)
+	}
+
+	conn.Close()
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error":   http.StatusText(statusCode),
+		"message": message,
+		"status":  statusCode,
+	})
+}
+
+func decodeBody(r *http.Request) (map[string]interface{}, error) {
+	var body map[string]interface{}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return body, nil
+}
+
+// copyMap returns a deep copy of m, so that callers cannot modify the
+// server's state.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	var c map[string]interface{}
+
+	b, _ := json.Marshal(m)
+	_ = json.Unmarshal(b, &c)
+
+	return c
+}
+
+func timestamp() string {
+	return fmt.Sprint(time.Now().UnixMilli())
+}
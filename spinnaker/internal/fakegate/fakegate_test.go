@@ -0,0 +1,169 @@
+package fakegate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func doRequest(t *testing.T, srv *Server, method, path string, body interface{}) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	var reader *strings.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = strings.NewReader(string(b))
+	} else {
+		reader = strings.NewReader("")
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, srv.URL+path, reader)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+
+	return resp, result
+}
+
+func TestTasks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.SetTaskPolls(2)
+
+	_, ref := doRequest(t, srv, http.MethodPost, "/tasks", map[string]interface{}{
+		"application": "myapp",
+		"job": []interface{}{
+			map[string]interface{}{
+				"type":        "createApplication",
+				"application": map[string]interface{}{"name": "MyApp", "email": "team@example.com"},
+			},
+		},
+	})
+
+	taskPath := ref["ref"].(string)
+
+	for _, status := range []string{"RUNNING", "RUNNING", "SUCCEEDED", "SUCCEEDED"} {
+		_, task := doRequest(t, srv, http.MethodGet, taskPath, nil)
+		require.Equal(t, status, task["status"])
+	}
+
+	app, ok := srv.Application("myapp")
+	require.True(t, ok)
+	require.Equal(t, "myapp", app["name"])
+	require.Equal(t, "team@example.com", app["email"])
+
+	resp, result := doRequest(t, srv, http.MethodGet, "/applications/myapp", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "team@example.com", result["attributes"].(map[string]interface{})["email"])
+
+	jobs := srv.Jobs("createApplication")
+	require.Len(t, jobs, 1)
+	require.Equal(t, "MyApp", jobs[0]["application"].(map[string]interface{})["name"])
+	require.Empty(t, srv.Jobs("updateApplication"))
+}
+
+func TestFailTasks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.FailTasks("deleteApplication", "Application has 2 server groups")
+
+	_, ref := doRequest(t, srv, http.MethodPost, "/tasks", map[string]interface{}{
+		"job": []interface{}{
+			map[string]interface{}{
+				"type":        "deleteApplication",
+				"application": map[string]interface{}{"name": "myapp"},
+			},
+		},
+	})
+
+	_, task := doRequest(t, srv, http.MethodGet, ref["ref"].(string), nil)
+	require.Equal(t, "TERMINAL", task["status"])
+
+	stage := task["execution"].(map[string]interface{})["stages"].([]interface{})[0].(map[string]interface{})
+	details := stage["context"].(map[string]interface{})["exception"].(map[string]interface{})["details"].(map[string]interface{})
+	require.Equal(t, []interface{}{"Application has 2 server groups"}, details["errors"])
+}
+
+func TestPipelines(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	pipeline := map[string]interface{}{"application": "myapp", "name": "deploy"}
+
+	resp, _ := doRequest(t, srv, http.MethodPost, "/pipelines", pipeline)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, result := doRequest(t, srv, http.MethodPost, "/pipelines", pipeline)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "A pipeline with name deploy already exists in application myapp", result["message"])
+
+	stored, ok := srv.Pipeline("myapp", "deploy")
+	require.True(t, ok)
+
+	id := stored["id"].(string)
+	stored["description"] = "updated"
+
+	resp, _ = doRequest(t, srv, http.MethodPut, "/pipelines/"+id, stored)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var history []map[string]interface{}
+
+	httpResp, err := srv.Client().Get(srv.URL + "/pipelineConfigs/" + id + "/history?limit=5")
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&history))
+	require.Len(t, history, 2)
+	require.Equal(t, "updated", history[0]["description"])
+
+	resp, _ = doRequest(t, srv, http.MethodDelete, "/pipelines/myapp/deploy", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, srv, http.MethodGet, "/applications/myapp/pipelineConfigs/deploy", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestInjectFault(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.InjectFault(Fault{
+		Method:     http.MethodGet,
+		Path:       "/version",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: "1",
+		Times:      1,
+	})
+
+	resp, _ := doRequest(t, srv, http.MethodGet, "/version", nil)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	resp, _ = doRequest(t, srv, http.MethodGet, "/version", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	srv.InjectFault(Fault{Path: "/version", EOF: true})
+
+	_, err := srv.Client().Get(srv.URL + "/version")
+	require.Error(t, err)
+
+	srv.ClearFaults()
+	srv.InjectFault(Fault{Path: "/version", Delay: 20 * time.Millisecond})
+
+	start := time.Now()
+	resp, _ = doRequest(t, srv, http.MethodGet, "/version", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
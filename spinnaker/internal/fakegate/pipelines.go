@@ -0,0 +1,243 @@
+package fakegate
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pipeline returns the current revision of the pipeline with name in
+// application and whether it exists.
+func (s *Server) Pipeline(application, name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.findPipeline(application, name)
+	if !ok {
+		return nil, false
+	}
+
+	return copyMap(s.pipelines[id][0]), true
+}
+
+// PutPipeline creates or replaces a pipeline, bypassing the pipeline API, and
+// returns its ID. A new ID is assigned if the pipeline does not have one.
+// Unlike saves through the API, a lastModifiedBy set on the pipeline is kept,
+// which allows simulating edits by other users.
+func (s *Server) PutPipeline(pipeline map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pipeline = copyMap(pipeline)
+
+	id, _ := pipeline["id"].(string)
+	if id == "" {
+		id = s.newID()
+		pipeline["id"] = id
+	}
+
+	s.storePipeline(id, pipeline)
+
+	return id
+}
+
+// findPipeline returns the ID of the pipeline with name in application. Must
+// be called with s.mu held.
+func (s *Server) findPipeline(application, name string) (string, bool) {
+	for id, revisions := range s.pipelines {
+		current := revisions[0]
+
+		if strings.EqualFold(current["application"].(string), application) && current["name"] == name {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// storePipeline records pipeline as the newest revision of the pipeline with
+// id. Must be called with s.mu held.
+func (s *Server) storePipeline(id string, pipeline map[string]interface{}) {
+	pipeline["id"] = id
+	pipeline["updateTs"] = timestamp()
+
+	if _, ok := pipeline["lastModifiedBy"]; !ok {
+		pipeline["lastModifiedBy"] = s.user
+	}
+
+	s.pipelines[id] = append([]map[string]interface{}{pipeline}, s.pipelines[id]...)
+}
+
+// validatePipeline ensures that pipeline has a name and an application and
+// that there is no other pipeline with the same name in the application.
+// Must be called with s.mu held.
+func (s *Server) validatePipeline(id string, pipeline map[string]interface{}) error {
+	application, _ := pipeline["application"].(string)
+	name, _ := pipeline["name"].(string)
+
+	if application == "" || name == "" {
+		return fmt.Errorf("pipeline must have an application and a name")
+	}
+
+	if existingID, ok := s.findPipeline(application, name); ok && existingID != id {
+		return fmt.Errorf("A pipeline with name %s already exists in application %s", name, application)
+	}
+
+	return nil
+}
+
+// servePipelines handles:
+//
+//	POST   /pipelines
+//	PUT    /pipelines/{id}
+//	DELETE /pipelines/{application}/{pipelineName}
+func (s *Server) servePipelines(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.savePipeline(w, r)
+	case len(segments) == 1 && r.Method == http.MethodPut:
+		s.updatePipeline(w, r, segments[0])
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		s.deletePipeline(w, segments[0], segments[1])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) savePipeline(w http.ResponseWriter, r *http.Request) {
+	pipeline, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, _ := pipeline["id"].(string)
+	if id == "" {
+		id = s.newID()
+	}
+
+	if err := s.validatePipeline(id, pipeline); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pipeline["lastModifiedBy"] = s.user
+	s.storePipeline(id, pipeline)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) updatePipeline(w http.ResponseWriter, r *http.Request, id string) {
+	pipeline, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pipelines[id]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline %s not found", id))
+		return
+	}
+
+	if bodyID, _ := pipeline["id"].(string); bodyID != "" && bodyID != id {
+		writeError(w, http.StatusBadRequest, "pipeline id in body does not match id in path")
+		return
+	}
+
+	if err := s.validatePipeline(id, pipeline); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pipeline["lastModifiedBy"] = s.user
+	s.storePipeline(id, pipeline)
+
+	writeJSON(w, http.StatusOK, copyMap(pipeline))
+}
+
+func (s *Server) deletePipeline(w http.ResponseWriter, application, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.findPipeline(application, name); ok {
+		delete(s.pipelines, id)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) listPipelineConfigs(w http.ResponseWriter, application string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pipelines := make([]map[string]interface{}, 0)
+
+	for _, revisions := range s.pipelines {
+		if strings.EqualFold(revisions[0]["application"].(string), application) {
+			pipelines = append(pipelines, copyMap(revisions[0]))
+		}
+	}
+
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i]["name"].(string) < pipelines[j]["name"].(string)
+	})
+
+	writeJSON(w, http.StatusOK, pipelines)
+}
+
+func (s *Server) getPipelineConfig(w http.ResponseWriter, application, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.findPipeline(application, name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Pipeline config (id: %s) not found", name))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, copyMap(s.pipelines[id][0]))
+}
+
+// servePipelineConfigs handles:
+//
+//	GET /pipelineConfigs/{pipelineConfigId}/history?limit={limit}
+func (s *Server) servePipelineConfigs(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet || len(segments) != 2 || segments[1] != "history" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	limit := 20
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		l, err := strconv.Atoi(rawLimit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", rawLimit))
+			return
+		}
+
+		limit = l
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions := s.pipelines[segments[0]]
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+
+	history := make([]interface{}, 0, len(revisions))
+	for _, revision := range revisions {
+		history = append(history, copyMap(revision))
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
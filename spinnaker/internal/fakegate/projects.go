@@ -0,0 +1,123 @@
+package fakegate
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Project returns the project with the given ID or name and whether it
+// exists.
+func (s *Server) Project(idOrName string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.findProject(idOrName)
+	if !ok {
+		return nil, false
+	}
+
+	return copyMap(s.projects[id]), true
+}
+
+// findProject returns the ID of the project with the given ID or name. Must
+// be called with s.mu held.
+func (s *Server) findProject(idOrName string) (string, bool) {
+	if _, ok := s.projects[idOrName]; ok {
+		return idOrName, true
+	}
+
+	for id, project := range s.projects {
+		if strings.EqualFold(project["name"].(string), idOrName) {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// upsertProject creates or updates a project from the project of an
+// upsertProject job. Must be called with s.mu held.
+func (s *Server) upsertProject(project map[string]interface{}) error {
+	name, _ := project["name"].(string)
+	if name == "" {
+		return fmt.Errorf("project name must not be empty")
+	}
+
+	id, _ := project["id"].(string)
+
+	if existingID, ok := s.findProject(name); ok && existingID != id {
+		return fmt.Errorf("a project with name %s already exists", name)
+	}
+
+	project = copyMap(project)
+
+	if id == "" {
+		id = s.newID()
+		project["id"] = id
+		project["createTs"] = timestamp()
+	} else if existing, ok := s.projects[id]; ok {
+		project["createTs"] = existing["createTs"]
+	} else {
+		return fmt.Errorf("project %s does not exist", id)
+	}
+
+	project["updateTs"] = timestamp()
+
+	s.projects[id] = project
+
+	return nil
+}
+
+// deleteProject deletes the project of a deleteProject job. Must be called
+// with s.mu held.
+func (s *Server) deleteProject(project map[string]interface{}) error {
+	id, _ := project["id"].(string)
+
+	if _, ok := s.projects[id]; !ok {
+		return fmt.Errorf("project %s does not exist", id)
+	}
+
+	delete(s.projects, id)
+
+	return nil
+}
+
+// serveProjects handles:
+//
+//	GET /projects
+//	GET /projects/{idOrName}
+func (s *Server) serveProjects(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch len(segments) {
+	case 0:
+		projects := make([]map[string]interface{}, 0, len(s.projects))
+		for _, project := range s.projects {
+			projects = append(projects, copyMap(project))
+		}
+
+		sort.Slice(projects, func(i, j int) bool {
+			return projects[i]["name"].(string) < projects[j]["name"].(string)
+		})
+
+		writeJSON(w, http.StatusOK, projects)
+	case 1:
+		id, ok := s.findProject(segments[0])
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Project not found (id: %s)", segments[0]))
+			return
+		}
+
+		writeJSON(w, http.StatusOK, copyMap(s.projects[id]))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
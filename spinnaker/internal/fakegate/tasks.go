@@ -0,0 +1,181 @@
+package fakegate
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type task struct {
+	id          string
+	application string
+	jobs        []map[string]interface{}
+	polls       int
+	status      string
+	stageErrors []string
+	outputs     map[string]interface{}
+}
+
+func (t *task) toMap() map[string]interface{} {
+	stage := map[string]interface{}{
+		"outputs": t.outputs,
+		"context": map[string]interface{}{},
+	}
+
+	if len(t.stageErrors) > 0 {
+		errs := make([]interface{}, 0, len(t.stageErrors))
+		for _, e := range t.stageErrors {
+			errs = append(errs, e)
+		}
+
+		stage["context"] = map[string]interface{}{
+			"exception": map[string]interface{}{
+				"details": map[string]interface{}{"errors": errs},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"id":          t.id,
+		"application": t.application,
+		"status":      t.status,
+		"execution": map[string]interface{}{
+			"stages": []interface{}{stage},
+		},
+	}
+}
+
+// Jobs returns the jobs of type jobType of all submitted tasks in the order
+// they were submitted.
+func (s *Server) Jobs(jobType string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []map[string]interface{}
+	for _, job := range s.jobs {
+		if job["type"] == jobType {
+			jobs = append(jobs, copyMap(job))
+		}
+	}
+
+	return jobs
+}
+
+// serveTasks handles:
+//
+//	POST /tasks
+//	GET  /tasks/{id}
+func (s *Server) serveTasks(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.submitTask(w, r)
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getTask(w, segments[0])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) submitTask(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rawJobs, _ := body["job"].([]interface{})
+	if len(rawJobs) == 0 {
+		writeError(w, http.StatusBadRequest, "task does not contain any jobs")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &task{
+		id:      s.newID(),
+		status:  "NOT_STARTED",
+		outputs: make(map[string]interface{}),
+	}
+	t.application, _ = body["application"].(string)
+
+	for _, j := range rawJobs {
+		job, ok := j.(map[string]interface{})
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid job: %v", j))
+			return
+		}
+
+		t.jobs = append(t.jobs, job)
+	}
+
+	s.jobs = append(s.jobs, t.jobs...)
+
+	s.tasks[t.id] = t
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ref": "/tasks/" + t.id})
+}
+
+func (s *Server) getTask(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("task %s not found", id))
+		return
+	}
+
+	if t.status == "NOT_STARTED" || t.status == "RUNNING" {
+		t.polls++
+
+		if t.polls <= s.taskPolls {
+			t.status = "RUNNING"
+		} else {
+			s.runTask(t)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, t.toMap())
+}
+
+// runTask applies the jobs of t to the server state and records the outcome
+// on t. Must be called with s.mu held.
+func (s *Server) runTask(t *task) {
+	for _, job := range t.jobs {
+		jobType, _ := job["type"].(string)
+
+		if message, ok := s.taskFailures[jobType]; ok {
+			t.status = "TERMINAL"
+			t.stageErrors = append(t.stageErrors, message)
+			return
+		}
+
+		if err := s.runJob(jobType, job); err != nil {
+			t.status = "TERMINAL"
+			t.stageErrors = append(t.stageErrors, err.Error())
+			return
+		}
+	}
+
+	t.status = "SUCCEEDED"
+}
+
+func (s *Server) runJob(jobType string, job map[string]interface{}) error {
+	switch jobType {
+	case "createApplication", "updateApplication":
+		app, _ := job["application"].(map[string]interface{})
+		return s.saveApplication(jobType == "createApplication", app)
+	case "deleteApplication":
+		app, _ := job["application"].(map[string]interface{})
+		name, _ := app["name"].(string)
+		return s.deleteApplication(name)
+	case "upsertProject":
+		project, _ := job["project"].(map[string]interface{})
+		return s.upsertProject(project)
+	case "deleteProject":
+		project, _ := job["project"].(map[string]interface{})
+		return s.deleteProject(project)
+	default:
+		return fmt.Errorf("unsupported job type %q", jobType)
+	}
+}
@@ -0,0 +1,303 @@
+package fakegate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// latestTag is the tag under which the most recently saved version of a V2
+// pipeline template is stored.
+const latestTag = "latest"
+
+// PipelineTemplate returns the V1 pipeline template with id and whether it
+// exists.
+func (s *Server) PipelineTemplate(id string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[id]
+
+	return copyMap(template), ok
+}
+
+// PipelineTemplateV2 returns the version of the V2 pipeline template with id
+// that is tagged with tag and whether it exists. An empty tag refers to the
+// latest version.
+func (s *Server) PipelineTemplateV2(id, tag string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tag == "" {
+		tag = latestTag
+	}
+
+	template, ok := s.templatesV2[id][tag]
+
+	return copyMap(template), ok
+}
+
+// servePipelineTemplates handles:
+//
+//	POST   /pipelineTemplates
+//	GET    /pipelineTemplates/{id}
+//	POST   /pipelineTemplates/{id}
+//	DELETE /pipelineTemplates/{id}
+func (s *Server) servePipelineTemplates(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.savePipelineTemplate(w, r, "")
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		s.savePipelineTemplate(w, r, segments[0])
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getPipelineTemplate(w, segments[0])
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		s.deletePipelineTemplate(w, segments[0])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) savePipelineTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	template, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	templateID, _ := template["id"].(string)
+	if templateID == "" || (id != "" && templateID != id) {
+		writeError(w, http.StatusBadRequest, "template id must be set and match the id in the path")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.templates[templateID]
+
+	switch {
+	case id == "" && exists:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("pipeline template %s already exists", templateID))
+		return
+	case id != "" && !exists:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", templateID))
+		return
+	}
+
+	template["updateTs"] = timestamp()
+	template["lastModifiedBy"] = "anonymous"
+
+	s.templates[templateID] = template
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getPipelineTemplate(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, copyMap(template))
+}
+
+func (s *Server) deletePipelineTemplate(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.templates, id)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{})
+}
+
+// servePipelineTemplatesV2 handles:
+//
+//	POST   /v2/pipelineTemplates/create?tag={tag}
+//	POST   /v2/pipelineTemplates/update/{id}?tag={tag}
+//	GET    /v2/pipelineTemplates/versions
+//	GET    /v2/pipelineTemplates/{id}?tag={tag}&digest={digest}
+//	DELETE /v2/pipelineTemplates/{id}?tag={tag}&digest={digest}
+func (s *Server) servePipelineTemplatesV2(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && segments[0] == "create" && r.Method == http.MethodPost:
+		s.savePipelineTemplateV2(w, r, "")
+	case len(segments) == 2 && segments[0] == "update" && r.Method == http.MethodPost:
+		s.savePipelineTemplateV2(w, r, segments[1])
+	case len(segments) == 1 && segments[0] == "versions" && r.Method == http.MethodGet:
+		s.listPipelineTemplateV2Versions(w)
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getPipelineTemplateV2(w, r, segments[0])
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		s.deletePipelineTemplateV2(w, r, segments[0])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) savePipelineTemplateV2(w http.ResponseWriter, r *http.Request, id string) {
+	template, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	templateID, _ := template["id"].(string)
+	if templateID == "" || (id != "" && templateID != id) {
+		writeError(w, http.StatusBadRequest, "template id must be set and match the id in the path")
+		return
+	}
+
+	tag := r.URL.Query().Get("tag")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, exists := s.templatesV2[templateID]
+
+	switch {
+	case id == "" && exists:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("pipeline template %s already exists", templateID))
+		return
+	case id != "" && !exists:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", templateID))
+		return
+	case !exists:
+		versions = make(map[string]map[string]interface{})
+		s.templatesV2[templateID] = versions
+	}
+
+	delete(template, "tag")
+	delete(template, "digest")
+
+	digest := templateDigest(template)
+
+	template["updateTs"] = timestamp()
+	template["lastModifiedBy"] = "anonymous"
+	template["digest"] = digest
+
+	// Saving a tagged version also updates the latest version.
+	latest := copyMap(template)
+	latest["tag"] = latestTag
+	versions[latestTag] = latest
+
+	if tag != "" && tag != latestTag {
+		template["tag"] = tag
+		versions[tag] = template
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) getPipelineTemplateV2(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.findPipelineTemplateV2Version(id, r.URL.Query().Get("tag"), r.URL.Query().Get("digest"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, copyMap(template))
+}
+
+func (s *Server) deletePipelineTemplateV2(w http.ResponseWriter, r *http.Request, id string) {
+	tag := r.URL.Query().Get("tag")
+	digest := r.URL.Query().Get("digest")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, ok := s.templatesV2[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", id))
+		return
+	}
+
+	deleted := false
+
+	for versionTag, template := range versions {
+		if (digest != "" && template["digest"] == digest) ||
+			(digest == "" && tag != "" && versionTag == tag) ||
+			(digest == "" && tag == "" && versionTag == latestTag) {
+			delete(versions, versionTag)
+			deleted = true
+		}
+	}
+
+	if !deleted {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("pipeline template %s not found", id))
+		return
+	}
+
+	if len(versions) == 0 {
+		delete(s.templatesV2, id)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) listPipelineTemplateV2Versions(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]interface{}, len(s.templatesV2))
+
+	for id, versions := range s.templatesV2 {
+		tags := make([]string, 0, len(versions))
+		for tag := range versions {
+			tags = append(tags, tag)
+		}
+
+		sort.Strings(tags)
+
+		list := make([]interface{}, 0, len(tags))
+		for _, tag := range tags {
+			list = append(list, copyMap(versions[tag]))
+		}
+
+		result[id] = list
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// findPipelineTemplateV2Version looks up the version of the template with id
+// by digest or tag. The latest version is returned if neither is set. Must be
+// called with s.mu held.
+func (s *Server) findPipelineTemplateV2Version(id, tag, digest string) (map[string]interface{}, bool) {
+	versions := s.templatesV2[id]
+
+	if digest != "" {
+		for _, template := range versions {
+			if template["digest"] == digest {
+				return template, true
+			}
+		}
+
+		return nil, false
+	}
+
+	if tag == "" {
+		tag = latestTag
+	}
+
+	template, ok := versions[tag]
+
+	return template, ok
+}
+
+func templateDigest(template map[string]interface{}) string {
+	b, _ := json.Marshal(template)
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,161 @@
+package spinnaker
+
+import (
+	"reflect"
+	"strings"
+)
+
+// managedPipelineKeys are set by Spinnaker or handled by other schema
+// attributes and are therefore never compared.
+var managedPipelineKeys = []string{
+	"application",
+	"id",
+	"index",
+	"lastModifiedBy",
+	"name",
+	"schema",
+	"updateTs",
+}
+
+// pipelineDefaults are the values Front50 and Deck fill in for pipeline
+// attributes that are missing. A pipeline without one of these keys is
+// equal to a pipeline that sets it to its default.
+var pipelineDefaults = map[string]interface{}{
+	"appConfig":            map[string]interface{}{},
+	"disabled":             false,
+	"expectedArtifacts":    []interface{}{},
+	"keepWaitingPipelines": false,
+	"limitConcurrent":      true,
+	"notifications":        []interface{}{},
+	"parameterConfig":      []interface{}{},
+	"spelEvaluator":        "v4",
+	"triggers":             []interface{}{},
+}
+
+// stageDefaults are the defaults shared by all stage types.
+var stageDefaults = map[string]interface{}{
+	"completeOtherBranchesThenFail":     false,
+	"continuePipeline":                  false,
+	"failOnFailedExpressions":           false,
+	"failPipeline":                      true,
+	"isNew":                             true,
+	"notifications":                     []interface{}{},
+	"requisiteStageRefIds":              []interface{}{},
+	"restrictExecutionDuringTimeWindow": false,
+	"sendNotifications":                 false,
+}
+
+// stageTypeDefaults are the defaults of common stage types, keyed by the
+// stage type.
+var stageTypeDefaults = map[string]map[string]interface{}{
+	"checkPreconditions": {
+		"preconditions": []interface{}{},
+	},
+	"deleteManifest": {
+		"options": map[string]interface{}{"cascading": true},
+	},
+	"deployManifest": {
+		"skipExpressionEvaluation": false,
+		"source":                   "text",
+		"trafficManagement": map[string]interface{}{
+			"enabled": false,
+			"options": map[string]interface{}{
+				"enableTraffic": false,
+				"services":      []interface{}{},
+			},
+		},
+	},
+	"jenkins": {
+		"markUnstableAsSuccessful": false,
+		"waitForCompletion":        true,
+	},
+	"manualJudgment": {
+		"judgmentInputs": []interface{}{},
+	},
+	"pipeline": {
+		"waitForCompletion": true,
+	},
+	"runJobManifest": {
+		"source": "text",
+	},
+}
+
+// normalizePipeline removes all keys from the pipeline that are managed by
+// Spinnaker or set to the value Spinnaker would fill in anyway, so that two
+// pipelines only differ if they behave differently.
+func normalizePipeline(pipeline map[string]interface{}) {
+	for _, key := range managedPipelineKeys {
+		delete(pipeline, key)
+	}
+
+	removeDefaults(pipeline, pipelineDefaults)
+
+	stages, _ := pipeline["stages"].([]interface{})
+	for _, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		removeStageDefaults(stage, stringValue(stage["type"]))
+	}
+}
+
+// removeStageDefaults removes the keys from the stage that are set to the
+// defaults of all stages or of stages of the given type.
+func removeStageDefaults(stage map[string]interface{}, stageType string) {
+	removeDefaults(stage, stageDefaults)
+	removeDefaults(stage, stageTypeDefaults[stageType])
+}
+
+func removeDefaults(m map[string]interface{}, defaults map[string]interface{}) {
+	for key, value := range defaults {
+		if v, ok := m[key]; ok && reflect.DeepEqual(v, value) {
+			delete(m, key)
+		}
+	}
+}
+
+// ignorePipelineKeys replaces the values of the given keys in the pipeline
+// with the values found in prior, or removes them if prior does not contain
+// them. Keys are dot separated paths; a path that descends into a list
+// applies to every element of the list, e.g. "stages.refId".
+func ignorePipelineKeys(pipeline, prior map[string]interface{}, keys []string) {
+	for _, key := range keys {
+		copyPipelineKey(pipeline, prior, strings.Split(key, "."))
+	}
+}
+
+func copyPipelineKey(dst, src map[string]interface{}, path []string) {
+	key := path[0]
+
+	if len(path) == 1 {
+		if v, ok := src[key]; ok {
+			dst[key] = v
+		} else {
+			delete(dst, key)
+		}
+		return
+	}
+
+	switch d := dst[key].(type) {
+	case map[string]interface{}:
+		s, _ := src[key].(map[string]interface{})
+		copyPipelineKey(d, s, path[1:])
+	case []interface{}:
+		s, _ := src[key].([]interface{})
+		for i := range d {
+			element, ok := d[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			var prior map[string]interface{}
+			if i < len(s) {
+				prior, _ = s[i].(map[string]interface{})
+			}
+
+			copyPipelineKey(element, prior, path[1:])
+		}
+	}
+}
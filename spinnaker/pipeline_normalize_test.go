@@ -0,0 +1,126 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePipeline(t *testing.T) {
+	pipeline, err := parsePipeline(`{
+		"id": "4a3b7b5c",
+		"application": "myapp",
+		"name": "deploy",
+		"updateTs": "1700000000000",
+		"limitConcurrent": true,
+		"keepWaitingPipelines": false,
+		"spelEvaluator": "v4",
+		"triggers": [],
+		"description": "Deploys myapp",
+		"stages": [
+			{
+				"refId": "1",
+				"type": "deployManifest",
+				"failPipeline": true,
+				"requisiteStageRefIds": [],
+				"source": "text",
+				"skipExpressionEvaluation": true
+			},
+			{
+				"refId": "2",
+				"type": "wait",
+				"failPipeline": false,
+				"source": "text"
+			}
+		]
+	}`)
+	require.NoError(t, err)
+
+	normalizePipeline(pipeline)
+
+	require.Equal(t, map[string]interface{}{
+		"description": "Deploys myapp",
+		"stages": []interface{}{
+			map[string]interface{}{
+				"refId":                    "1",
+				"type":                     "deployManifest",
+				"skipExpressionEvaluation": true,
+			},
+			map[string]interface{}{
+				"refId":        "2",
+				"type":         "wait",
+				"failPipeline": false,
+				"source":       "text",
+			},
+		},
+	}, pipeline)
+}
+
+func TestPipelineDiffSuppressFunc(t *testing.T) {
+	data := resourcePipeline().TestResourceData()
+
+	require.True(t, pipelineDiffSuppressFunc("pipeline",
+		`{"stages":[{"refId":"1","type":"wait"}]}`,
+		`{"limitConcurrent":true,"triggers":[],"stages":[{"refId":"1","type":"wait","failPipeline":true}]}`,
+		data))
+	require.False(t, pipelineDiffSuppressFunc("pipeline",
+		`{"stages":[]}`,
+		`{"limitConcurrent":false,"stages":[]}`,
+		data))
+
+	require.NoError(t, data.Set("ignore_pipeline_keys", []interface{}{"stages.comments"}))
+	require.True(t, pipelineDiffSuppressFunc("pipeline",
+		`{"stages":[{"refId":"1","comments":"old"}]}`,
+		`{"stages":[{"refId":"1","comments":"new"}]}`,
+		data))
+}
+
+func TestIgnorePipelineKeys(t *testing.T) {
+	pipeline := map[string]interface{}{
+		"owner": "spinnaker",
+		"stages": []interface{}{
+			map[string]interface{}{"refId": "1", "comments": "added"},
+			map[string]interface{}{"refId": "2", "comments": "changed"},
+		},
+	}
+	prior := map[string]interface{}{
+		"stages": []interface{}{
+			map[string]interface{}{"refId": "1"},
+			map[string]interface{}{"refId": "2", "comments": "original"},
+		},
+	}
+
+	ignorePipelineKeys(pipeline, prior, []string{"owner", "stages.comments", "triggers.enabled"})
+
+	require.Equal(t, map[string]interface{}{
+		"stages": []interface{}{
+			map[string]interface{}{"refId": "1"},
+			map[string]interface{}{"refId": "2", "comments": "original"},
+		},
+	}, pipeline)
+}
+
+func TestResourcePipelineReadIgnoresProviderKeys(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	meta.ignorePipelineKeys = []string{"owner"}
+
+	r := resourcePipeline()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"stages":[]}`,
+	})
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	pipeline, _ := srv.Pipeline("myapp", "deploy")
+	pipeline["owner"] = "someone"
+	pipeline["limitConcurrent"] = true
+	srv.PutPipeline(pipeline)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Equal(t, `{"stages":[]}`, data.Get("pipeline"))
+}
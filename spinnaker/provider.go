@@ -1,12 +1,18 @@
 package spinnaker
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	gate "github.com/spinnaker/spin/cmd/gateclient"
 	"github.com/spinnaker/spin/cmd/output"
+	"github.com/spinnaker/spin/config/auth"
 )
 
 func Provider() *schema.Provider {
@@ -36,6 +42,189 @@ func Provider() *schema.Provider {
 				Description: "Headers to be passed to the gate endpoint by the client on each request",
 				Default:     "",
 			},
+			"ignore_pipeline_keys": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Pipeline keys to ignore when comparing pipelines, e.g. keys added by site-specific Spinnaker plugins",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Retry policy for failed requests to Gate",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_attempts": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      6,
+							Description:  "Maximum number of attempts per request, including the first one",
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"initial_interval": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "2s",
+							Description:  "Time to wait before the first retry",
+							ValidateFunc: validateDuration,
+						},
+						"max_interval": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "30s",
+							Description:  "Maximum time to wait between two retries",
+							ValidateFunc: validateDuration,
+						},
+						"multiplier": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							Default:      1.5,
+							Description:  "Factor the wait time grows by after each retry",
+							ValidateFunc: validation.FloatAtLeast(1),
+						},
+						"retry_on_status_codes": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "HTTP status codes to retry on. Defaults to 429 and all 5xx status codes",
+							Elem: &schema.Schema{
+								Type:         schema.TypeInt,
+								ValidateFunc: validation.IntBetween(100, 599),
+							},
+						},
+					},
+				},
+			},
+			"x509": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				Description:   "X.509 client certificate authentication",
+				ConflictsWith: []string{"config"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cert": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Description:   "PEM encoded client certificate",
+							ConflictsWith: []string{"x509.0.cert_path"},
+							RequiredWith:  []string{"x509.0.key"},
+						},
+						"key": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Sensitive:     true,
+							Description:   "PEM encoded private key of the client certificate",
+							ConflictsWith: []string{"x509.0.key_path"},
+							RequiredWith:  []string{"x509.0.cert"},
+						},
+						"cert_path": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Path to the PEM encoded client certificate",
+							RequiredWith: []string{"x509.0.key_path"},
+						},
+						"key_path": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Path to the PEM encoded private key of the client certificate",
+							RequiredWith: []string{"x509.0.cert_path"},
+						},
+					},
+				},
+			},
+			"basic_auth": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				Description:   "HTTP basic authentication",
+				ConflictsWith: []string{"config", "oauth2", "google_iap"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Username for basic authentication",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "Password for basic authentication",
+						},
+					},
+				},
+			},
+			"oauth2": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				Description:   "OAuth2 bearer token authentication",
+				ConflictsWith: []string{"config", "basic_auth", "google_iap"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"token": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							Description:  "Bearer token sent with each request",
+							ExactlyOneOf: []string{"oauth2.0.token", "oauth2.0.token_command"},
+						},
+						"token_command": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Shell command that prints the bearer token to stdout",
+							ExactlyOneOf: []string{"oauth2.0.token", "oauth2.0.token_command"},
+						},
+					},
+				},
+			},
+			"google_iap": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				Description:   "Google Identity-Aware Proxy authentication",
+				ConflictsWith: []string{"config", "basic_auth", "oauth2"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"iap_client_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "OAuth client ID of the IAP protecting Gate",
+						},
+						"iap_client_refresh": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Refresh token used to obtain IAP ID tokens",
+						},
+						"iap_id_token": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Pre-fetched IAP ID token",
+						},
+						"oauth_client_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "OAuth client ID used together with iap_client_refresh",
+						},
+						"oauth_client_secret": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "OAuth client secret used together with iap_client_refresh",
+						},
+						"service_account_key_path": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Path to a Google service account key used to obtain IAP ID tokens",
+						},
+					},
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"spinnaker_application":              resourceApplication(),
@@ -43,9 +232,17 @@ func Provider() *schema.Provider {
 			"spinnaker_pipeline_template":        resourcePipelineTemplate(),
 			"spinnaker_pipeline_template_config": resourcePipelineTemplateConfig(),
 			"spinnaker_pipeline_template_v2":     resourcePipelineTemplateV2(),
+			"spinnaker_project":                  resourceProject(),
+			"spinnaker_structured_pipeline":      resourceStructuredPipeline(),
+			"spinnaker_templated_pipeline":       resourceTemplatedPipeline(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"spinnaker_pipeline": datasourcePipeline(),
+			"spinnaker_application":          datasourceApplication(),
+			"spinnaker_applications":         datasourceApplications(),
+			"spinnaker_pipeline":             datasourcePipeline(),
+			"spinnaker_pipeline_history":     datasourcePipelineHistory(),
+			"spinnaker_pipeline_template_v2": datasourcePipelineTemplateV2(),
+			"spinnaker_pipelines":            datasourcePipelines(),
 		},
 		ConfigureFunc: providerConfigureFunc,
 	}
@@ -57,11 +254,23 @@ type clientConfig struct {
 	configLocation   string
 	ignoreCertErrors bool
 	ignoreRedirects  bool
-	retryTimeout     int
+
+	retryPolicy api.RetryPolicy
+
+	ignorePipelineKeys []string
+
+	// auth is set if authentication is configured via provider attributes
+	// instead of a spin config file.
+	auth         *auth.Config
+	token        string
+	tokenCommand string
 
 	once   sync.Once
 	client *gate.GatewayClient
 	err    error
+
+	userMu sync.Mutex
+	user   string
 }
 
 // Client lazily initializes a *gate.GatewayClient on the first call and
@@ -69,20 +278,56 @@ type clientConfig struct {
 // error if client initialization fails.
 func (c *clientConfig) Client() (*gate.GatewayClient, error) {
 	c.once.Do(func() {
-		c.client, c.err = gate.NewGateClient(
-			output.NewUI(true, false, output.MarshalToJson, os.Stdout, os.Stderr),
-			c.gateEndpoint,
-			c.defaultHeaders,
-			c.configLocation,
-			c.ignoreCertErrors,
-			c.ignoreRedirects,
-			c.retryTimeout,
-		)
+		if c.auth != nil {
+			c.client, c.err = newGateClient(c)
+		} else {
+			c.client, c.err = gate.NewGateClient(
+				output.NewUI(true, false, output.MarshalToJson, os.Stdout, os.Stderr),
+				c.gateEndpoint,
+				c.defaultHeaders,
+				c.configLocation,
+				c.ignoreCertErrors,
+				c.ignoreRedirects,
+				// Requests are retried according to retryPolicy, the
+				// retry timeout of the spin client is not used.
+				0,
+			)
+		}
+
+		if c.err == nil {
+			c.client.Context = api.ContextWithRetryPolicy(c.client.Context, c.retryPolicy)
+		}
 	})
 
 	return c.client, c.err
 }
 
+// CurrentUser returns the name of the user the provider authenticates as.
+// The user is fetched from Gate until a lookup succeeds and cached
+// afterwards.
+func (c *clientConfig) CurrentUser(ctx context.Context) (string, error) {
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+
+	if c.user != "" {
+		return c.user, nil
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		return "", err
+	}
+
+	user, err := api.GetCurrentUser(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	c.user = user
+
+	return user, nil
+}
+
 func providerConfigureFunc(data *schema.ResourceData) (interface{}, error) {
 	c := &clientConfig{
 		gateEndpoint:     data.Get("server").(string),
@@ -91,5 +336,58 @@ func providerConfigureFunc(data *schema.ResourceData) (interface{}, error) {
 		ignoreCertErrors: data.Get("ignore_cert_errors").(bool),
 	}
 
+	retryPolicy, err := expandRetryPolicy(data.Get("retry").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	c.retryPolicy = retryPolicy
+
+	for _, key := range data.Get("ignore_pipeline_keys").([]interface{}) {
+		c.ignorePipelineKeys = append(c.ignorePipelineKeys, key.(string))
+	}
+
+	if err := configureAuth(c, data); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
+
+func expandRetryPolicy(config []interface{}) (api.RetryPolicy, error) {
+	policy := api.DefaultRetryPolicy()
+
+	if len(config) == 0 || config[0] == nil {
+		return policy, nil
+	}
+
+	m := config[0].(map[string]interface{})
+
+	initialInterval, err := time.ParseDuration(m["initial_interval"].(string))
+	if err != nil {
+		return policy, fmt.Errorf("retry: invalid initial_interval: %w", err)
+	}
+
+	maxInterval, err := time.ParseDuration(m["max_interval"].(string))
+	if err != nil {
+		return policy, fmt.Errorf("retry: invalid max_interval: %w", err)
+	}
+
+	policy.MaxAttempts = m["max_attempts"].(int)
+	policy.InitialInterval = initialInterval
+	policy.MaxInterval = maxInterval
+	policy.Multiplier = m["multiplier"].(float64)
+
+	for _, code := range m["retry_on_status_codes"].([]interface{}) {
+		policy.RetryOnStatusCodes = append(policy.RetryOnStatusCodes, code.(int))
+	}
+
+	return policy, nil
+}
+
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := time.ParseDuration(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a valid duration: %w", key, err))
+	}
+	return
+}
@@ -0,0 +1,199 @@
+package spinnaker
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+	"github.com/spinnaker/spin/config/auth"
+	"github.com/spinnaker/spin/config/auth/basic"
+	iap "github.com/spinnaker/spin/config/auth/iap"
+	"github.com/spinnaker/spin/config/auth/x509"
+	gateapi "github.com/spinnaker/spin/gateapi"
+)
+
+// configureAuth populates the authentication settings of c from the x509,
+// basic_auth, oauth2 and google_iap provider blocks. c.auth is left nil if
+// none of them is configured, in which case authentication is handled by the
+// spin config file.
+func configureAuth(c *clientConfig, data *schema.ResourceData) error {
+	authConfig := &auth.Config{
+		Enabled:          true,
+		IgnoreCertErrors: c.ignoreCertErrors,
+	}
+
+	configured := false
+
+	if v, ok := data.GetOk("x509"); ok && v.([]interface{})[0] != nil {
+		m := v.([]interface{})[0].(map[string]interface{})
+		authConfig.X509 = &x509.Config{
+			Cert:     m["cert"].(string),
+			Key:      m["key"].(string),
+			CertPath: m["cert_path"].(string),
+			KeyPath:  m["key_path"].(string),
+		}
+
+		if !authConfig.X509.IsValid() || (authConfig.X509.Cert == "" && authConfig.X509.CertPath == "") {
+			return errors.New("x509: either cert and key or cert_path and key_path must be set")
+		}
+
+		configured = true
+	}
+
+	if v, ok := data.GetOk("basic_auth"); ok && v.([]interface{})[0] != nil {
+		m := v.([]interface{})[0].(map[string]interface{})
+		authConfig.Basic = &basic.Config{
+			Username: m["username"].(string),
+			Password: m["password"].(string),
+		}
+		configured = true
+	}
+
+	if v, ok := data.GetOk("oauth2"); ok && v.([]interface{})[0] != nil {
+		m := v.([]interface{})[0].(map[string]interface{})
+		c.token = m["token"].(string)
+		c.tokenCommand = m["token_command"].(string)
+		configured = true
+	}
+
+	if v, ok := data.GetOk("google_iap"); ok && v.([]interface{})[0] != nil {
+		m := v.([]interface{})[0].(map[string]interface{})
+		authConfig.Iap = &iap.Config{
+			IapClientId:           m["iap_client_id"].(string),
+			IapClientRefresh:      m["iap_client_refresh"].(string),
+			IapIdToken:            m["iap_id_token"].(string),
+			OAuthClientId:         m["oauth_client_id"].(string),
+			OAuthClientSecret:     m["oauth_client_secret"].(string),
+			ServiceAccountKeyPath: m["service_account_key_path"].(string),
+		}
+
+		// Without any of these the spin library falls back to an
+		// interactive browser login, which cannot work within terraform.
+		if authConfig.Iap.IapIdToken == "" && authConfig.Iap.IapClientRefresh == "" && authConfig.Iap.ServiceAccountKeyPath == "" {
+			return errors.New("google_iap: one of iap_id_token, iap_client_refresh or service_account_key_path must be set")
+		}
+
+		configured = true
+	}
+
+	if configured {
+		c.auth = authConfig
+	}
+
+	return nil
+}
+
+// newGateClient creates a *gate.GatewayClient that authenticates using the
+// settings from the provider configuration instead of a spin config file.
+func newGateClient(c *clientConfig) (*gate.GatewayClient, error) {
+	httpClient, err := gate.InitializeHTTPClient(c.auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize http client: %w", err)
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("failed to initialize http client: unexpected transport %T", httpClient.Transport)
+	}
+
+	if c.ignoreCertErrors {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	if c.ignoreRedirects {
+		httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	// Takes care of basic auth and IAP.
+	ctx, err := gate.ContextWithAuth(context.Background(), c.auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	token := c.token
+	if c.tokenCommand != "" {
+		token, err = runTokenCommand(c.tokenCommand)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if token != "" {
+		ctx = context.WithValue(ctx, gateapi.ContextAccessToken, token)
+	}
+
+	headers, err := parseDefaultHeaders(c.defaultHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &gate.GatewayClient{
+		APIClient: gateapi.NewAPIClient(&gateapi.Configuration{
+			BasePath:      c.gateEndpoint,
+			DefaultHeader: headers,
+			UserAgent:     "terraform-provider-spinnaker",
+			HTTPClient:    httpClient,
+		}),
+		Context: ctx,
+	}
+	client.Config.Gate.Endpoint = c.gateEndpoint
+	client.Config.Auth = c.auth
+
+	if _, err := api.GetVersion(api.ContextWithRetryPolicy(context.Background(), c.retryPolicy), client); err != nil {
+		return nil, fmt.Errorf("could not reach Gate at %s: %w", c.gateEndpoint, err)
+	}
+
+	return client, nil
+}
+
+// runTokenCommand executes command using the shell and returns its trimmed
+// output.
+func runTokenCommand(command string) (string, error) {
+	var stderr strings.Builder
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("oauth2 token_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("oauth2 token_command did not print a token")
+	}
+
+	return token, nil
+}
+
+// parseDefaultHeaders parses headers in the form "key1=value1,key2=value2".
+func parseDefaultHeaders(defaultHeaders string) (map[string]string, error) {
+	headers := make(map[string]string)
+
+	if defaultHeaders == "" {
+		return headers, nil
+	}
+
+	for _, element := range strings.Split(defaultHeaders, ",") {
+		header := strings.SplitN(element, "=", 2)
+		if len(header) != 2 {
+			return nil, fmt.Errorf("bad default_headers value, use key=value form: %s", element)
+		}
+		headers[strings.TrimSpace(header[0])] = strings.TrimSpace(header[1])
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,136 @@
+package spinnaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderAuth(t *testing.T) {
+	var authHeader string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			name: "basic auth",
+			config: map[string]interface{}{
+				"basic_auth": []interface{}{
+					map[string]interface{}{"username": "user", "password": "secret"},
+				},
+			},
+			expected: "Basic dXNlcjpzZWNyZXQ=",
+		},
+		{
+			name: "oauth2 token",
+			config: map[string]interface{}{
+				"oauth2": []interface{}{
+					map[string]interface{}{"token": "the-token"},
+				},
+			},
+			expected: "Bearer the-token",
+		},
+		{
+			name: "oauth2 token command",
+			config: map[string]interface{}{
+				"oauth2": []interface{}{
+					map[string]interface{}{"token_command": "echo ' command-token '"},
+				},
+			},
+			expected: "Bearer command-token",
+		},
+		{
+			name: "iap id token",
+			config: map[string]interface{}{
+				"google_iap": []interface{}{
+					map[string]interface{}{"iap_id_token": "iap-token"},
+				},
+			},
+			expected: "Bearer iap-token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authHeader = ""
+			test.config["server"] = srv.URL
+
+			data := schema.TestResourceDataRaw(t, Provider().Schema, test.config)
+
+			meta, err := providerConfigureFunc(data)
+			require.NoError(t, err)
+
+			c := meta.(*clientConfig)
+			require.NotNil(t, c.auth)
+
+			_, err = c.Client()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, authHeader)
+		})
+	}
+
+	t.Run("uses spin config without auth blocks", func(t *testing.T) {
+		data := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{"server": srv.URL})
+
+		meta, err := providerConfigureFunc(data)
+		require.NoError(t, err)
+		require.Nil(t, meta.(*clientConfig).auth)
+	})
+
+	t.Run("rejects iap without non-interactive credentials", func(t *testing.T) {
+		data := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+			"server": srv.URL,
+			"google_iap": []interface{}{
+				map[string]interface{}{"iap_client_id": "client"},
+			},
+		})
+
+		_, err := providerConfigureFunc(data)
+		require.EqualError(t, err, "google_iap: one of iap_id_token, iap_client_refresh or service_account_key_path must be set")
+	})
+}
+
+func TestProviderAuthRetriesVersionProbe(t *testing.T) {
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+	}))
+	defer srv.Close()
+
+	data := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"server": srv.URL,
+		"oauth2": []interface{}{
+			map[string]interface{}{"token": "the-token"},
+		},
+		"retry": []interface{}{
+			map[string]interface{}{"initial_interval": "1ms", "max_interval": "1ms"},
+		},
+	})
+
+	meta, err := providerConfigureFunc(data)
+	require.NoError(t, err)
+
+	_, err = meta.(*clientConfig).Client()
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+}
@@ -2,9 +2,14 @@ package spinnaker
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
 	"testing"
+	"time"
 
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/internal/fakegate"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -31,6 +36,89 @@ func testAccPreCheck(t *testing.T) {
 	}
 }
 
+// testUnitPreCheck skips tests that run Terraform against a fake Gate if no
+// terraform binary is available, except in CI.
+func testUnitPreCheck(t *testing.T) {
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" {
+		return
+	}
+
+	if _, err := exec.LookPath("terraform"); err != nil {
+		// CI installs terraform, so a missing binary must not silently skip
+		// the tests there.
+		if os.Getenv("CI") != "" {
+			t.Fatal("terraform binary not found in PATH")
+		}
+
+		t.Skip("terraform binary not found in PATH, set TF_ACC_TERRAFORM_PATH to run this test")
+	}
+}
+
+// testUnitProviderFactories returns provider factories for tests that run
+// Terraform against a fake Gate.
+func testUnitProviderFactories() map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"spinnaker": func() (*schema.Provider, error) {
+			return Provider(), nil
+		},
+	}
+}
+
+// testUnitProviderConfig returns a provider block for srv.
+func testUnitProviderConfig(srv *fakegate.Server) string {
+	return fmt.Sprintf(`
+provider "spinnaker" {
+	server = %q
+
+	retry {
+		initial_interval = "10ms"
+		max_interval     = "10ms"
+	}
+}
+`, srv.URL)
+}
+
+// newTestFakeGate starts a fake Gate server that is stopped at the end of the
+// test.
+func newTestFakeGate(t *testing.T) *fakegate.Server {
+	srv := fakegate.NewServer()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// newTestClientConfig returns a *clientConfig for srv that retries failed
+// requests without noticeable delay.
+func newTestClientConfig(srv *fakegate.Server) *clientConfig {
+	return &clientConfig{
+		gateEndpoint: srv.URL,
+		retryPolicy: api.RetryPolicy{
+			MaxAttempts:     3,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+			Multiplier:      1,
+		},
+	}
+}
+
+// testResourceDataUpdate returns the *schema.ResourceData for updating the
+// resource r from state to the configuration in raw.
+func testResourceDataUpdate(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	data, err := schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	return data
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
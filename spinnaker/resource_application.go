@@ -1,18 +1,29 @@
 package spinnaker
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// defaultInstancePort is the instance port of applications that do not set
+// one.
+const defaultInstancePort = 80
+
 func resourceApplication() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"application": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
 			},
 			"email": {
 				Type:     schema.TypeString,
@@ -30,105 +41,519 @@ func resourceApplication() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"cloud_providers": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"instance_port": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  defaultInstancePort,
+			},
+			"aliases": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"enable_restart_running_executions": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"platform_health_only": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"platform_health_only_show_override": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"traffic_guards": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"account": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"location": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"stack": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"detail": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+			"data_sources": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"disabled": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"custom_banners": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"text": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"background_color": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"text_color": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"permissions": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"read": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"write": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"execute": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
-		Create: resourceApplicationCreate,
-		Read:   resourceApplicationRead,
-		Update: resourceApplicationUpdate,
-		Delete: resourceApplicationDelete,
-		Exists: resourceApplicationExists,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceApplicationImport,
+		},
+		CustomizeDiff: resourceApplicationCustomizeDiff,
+		CreateContext: resourceApplicationCreate,
+		ReadContext:   resourceApplicationRead,
+		UpdateContext: resourceApplicationUpdate,
+		DeleteContext: resourceApplicationDelete,
 	}
 }
 
 type applicationRead struct {
 	Name       string `json:"name"`
 	Attributes struct {
-		Email          string `json:"email"`
-		RepoType       string `json:"repoType"`
-		RepoProjectKey string `json:"repoProjectKey"`
-		RepoSlug       string `json:"repoSlug"`
+		Email                          string         `json:"email"`
+		RepoType                       string         `json:"repoType"`
+		RepoProjectKey                 string         `json:"repoProjectKey"`
+		RepoSlug                       string         `json:"repoSlug"`
+		Description                    string         `json:"description"`
+		CloudProviders                 interface{}    `json:"cloudProviders"`
+		InstancePort                   *int           `json:"instancePort"`
+		Aliases                        interface{}    `json:"aliases"`
+		EnableRestartRunningExecutions bool           `json:"enableRestartRunningExecutions"`
+		PlatformHealthOnly             bool           `json:"platformHealthOnly"`
+		PlatformHealthOnlyShowOverride bool           `json:"platformHealthOnlyShowOverride"`
+		TrafficGuards                  []trafficGuard `json:"trafficGuards"`
+		DataSources                    *dataSources   `json:"dataSources"`
+		CustomBanners                  []customBanner `json:"customBanners"`
+		Permissions                    *permissions   `json:"permissions"`
+		Accounts                       interface{}    `json:"accounts"`
 	} `json:"attributes"`
+	Clusters map[string][]clusterSummary `json:"clusters"`
 }
 
-func resourceApplicationCreate(data *schema.ResourceData, meta interface{}) error {
-	return upsertApplication(data, meta)
+type clusterSummary struct {
+	Name string `json:"name"`
 }
 
-func resourceApplicationRead(data *schema.ResourceData, meta interface{}) error {
-	clientConfig := meta.(*clientConfig)
+type permissions struct {
+	Read    []string `json:"READ"`
+	Write   []string `json:"WRITE"`
+	Execute []string `json:"EXECUTE"`
+}
 
-	client, err := clientConfig.Client()
-	if err != nil {
-		return err
+type trafficGuard struct {
+	Account  string `json:"account"`
+	Location string `json:"location"`
+	Stack    string `json:"stack"`
+	Detail   string `json:"detail"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+type dataSources struct {
+	Enabled  []string `json:"enabled"`
+	Disabled []string `json:"disabled"`
+}
+
+type customBanner struct {
+	Text            string `json:"text"`
+	Enabled         *bool  `json:"enabled"`
+	BackgroundColor string `json:"backgroundColor"`
+	TextColor       string `json:"textColor"`
+}
+
+// resourceApplicationImport imports an application using its name as ID.
+func resourceApplicationImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := data.Set("application", data.Id()); err != nil {
+		return nil, err
 	}
 
-	applicationName := data.Get("application").(string)
-	var app applicationRead
-	if err := api.GetApplication(client, applicationName, &app); err != nil {
-		return err
+	return []*schema.ResourceData{data}, nil
+}
+
+func resourceApplicationCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	// Roles that come from other resources are not known until apply. The
+	// SDK only marks the count of a set with unknown elements as unknown.
+	for _, key := range []string{"permissions.#", "permissions.0.read.#", "permissions.0.execute.#"} {
+		if !diff.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	perms := diff.Get("permissions").([]interface{})
+	if len(perms) == 0 || perms[0] == nil {
+		return nil
 	}
 
-	return readApplication(data, app)
+	p := perms[0].(map[string]interface{})
+
+	return validateApplicationPermissions(
+		expandStringSet(p["read"].(*schema.Set)),
+		expandStringSet(p["execute"].(*schema.Set)),
+	)
 }
 
-func resourceApplicationUpdate(data *schema.ResourceData, meta interface{}) error {
-	return upsertApplication(data, meta)
+// validateApplicationPermissions ensures that every role that is allowed to
+// execute pipelines is also allowed to read the application, as Fiat would
+// otherwise hide the application from that role.
+func validateApplicationPermissions(read, execute []string) error {
+	readable := make(map[string]bool, len(read))
+	for _, role := range read {
+		readable[role] = true
+	}
+
+	var missing []string
+	for _, role := range execute {
+		if !readable[role] {
+			missing = append(missing, role)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("permissions: roles %q are granted EXECUTE without READ", missing)
+	}
+
+	return nil
 }
 
-func resourceApplicationDelete(data *schema.ResourceData, meta interface{}) error {
+func resourceApplicationCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	applicationName := data.Get("application").(string)
+	if err := api.CreateApplication(ctx, client, data, data.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
 
-	return api.DeleteAppliation(client, applicationName)
+	return resourceApplicationRead(ctx, data, meta)
 }
 
-func resourceApplicationExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+func resourceApplicationRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return false, err
+		return diag.FromErr(err)
 	}
 
 	applicationName := data.Get("application").(string)
-
 	var app applicationRead
-	if err := api.GetApplication(client, applicationName, &app); err != nil {
-		errmsg := err.Error()
-		if strings.Contains(errmsg, "not found") {
-			return false, nil
-		}
-		return false, err
+	err = api.GetApplication(ctx, client, applicationName, &app)
+	if apierrors.IsNotFound(err) {
+		data.SetId("")
+		return nil
+	} else if err != nil {
+		return diag.Errorf("failed to fetch application %q: %s", applicationName, err)
 	}
 
-	if app.Name == "" {
-		return false, nil
+	return diag.FromErr(readApplication(data, app))
+}
+
+func resourceApplicationUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	return true, nil
+	if err := api.UpdateApplication(ctx, client, data, data.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceApplicationRead(ctx, data, meta)
 }
 
-func upsertApplication(data *schema.ResourceData, meta interface{}) error {
+func resourceApplicationDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	if err := api.CreateApplication(client, data); err != nil {
-		return err
+	applicationName := data.Get("application").(string)
+
+	if err := api.DeleteApplication(ctx, client, applicationName, data.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceApplicationRead(data, meta)
+	return nil
 }
 
 func readApplication(data *schema.ResourceData, application applicationRead) error {
+	attrs := application.Attributes
+
+	if err := data.Set("email", attrs.Email); err != nil {
+		return err
+	}
+
+	if err := data.Set("repo_type", attrs.RepoType); err != nil {
+		return err
+	}
+
+	if err := data.Set("repo_slug", attrs.RepoSlug); err != nil {
+		return err
+	}
+
+	if err := data.Set("repo_project_key", attrs.RepoProjectKey); err != nil {
+		return err
+	}
+
+	if err := data.Set("description", attrs.Description); err != nil {
+		return err
+	}
+
+	if err := data.Set("cloud_providers", splitCommaSeparated(attrs.CloudProviders)); err != nil {
+		return err
+	}
+
+	// Applications created in Deck may not have an instance port, which is
+	// read as the default so that it does not cause a diff.
+	instancePort := defaultInstancePort
+	if attrs.InstancePort != nil {
+		instancePort = *attrs.InstancePort
+	}
+
+	if err := data.Set("instance_port", instancePort); err != nil {
+		return err
+	}
+
+	if err := data.Set("aliases", splitCommaSeparated(attrs.Aliases)); err != nil {
+		return err
+	}
+
+	if err := data.Set("enable_restart_running_executions", attrs.EnableRestartRunningExecutions); err != nil {
+		return err
+	}
+
+	if err := data.Set("platform_health_only", attrs.PlatformHealthOnly); err != nil {
+		return err
+	}
+
+	if err := data.Set("platform_health_only_show_override", attrs.PlatformHealthOnlyShowOverride); err != nil {
+		return err
+	}
+
+	if err := data.Set("traffic_guards", flattenTrafficGuards(attrs.TrafficGuards)); err != nil {
+		return err
+	}
+
+	if err := data.Set("data_sources", flattenDataSources(attrs.DataSources)); err != nil {
+		return err
+	}
+
+	if err := data.Set("custom_banners", flattenCustomBanners(attrs.CustomBanners)); err != nil {
+		return err
+	}
+
+	if err := data.Set("permissions", flattenPermissions(attrs.Permissions)); err != nil {
+		return err
+	}
+
 	data.SetId(application.Name)
 	return nil
 }
+
+// splitCommaSeparated normalizes attributes that Spinnaker stores either as
+// comma separated string (as done by Deck) or as a list of strings.
+func splitCommaSeparated(v interface{}) []string {
+	var result []string
+
+	switch value := v.(type) {
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	case []string:
+		result = append(result, value...)
+	}
+
+	return result
+}
+
+func flattenTrafficGuards(guards []trafficGuard) []interface{} {
+	result := make([]interface{}, 0, len(guards))
+
+	for _, guard := range guards {
+		// Traffic guards without the enabled flag are enabled.
+		enabled := true
+		if guard.Enabled != nil {
+			enabled = *guard.Enabled
+		}
+
+		result = append(result, map[string]interface{}{
+			"account":  guard.Account,
+			"location": guard.Location,
+			"stack":    guard.Stack,
+			"detail":   guard.Detail,
+			"enabled":  enabled,
+		})
+	}
+
+	return result
+}
+
+func flattenDataSources(sources *dataSources) []interface{} {
+	if sources == nil || (len(sources.Enabled) == 0 && len(sources.Disabled) == 0) {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"enabled":  sources.Enabled,
+			"disabled": sources.Disabled,
+		},
+	}
+}
+
+func flattenCustomBanners(banners []customBanner) []interface{} {
+	result := make([]interface{}, 0, len(banners))
+
+	for _, banner := range banners {
+		// Custom banners without the enabled flag are enabled.
+		enabled := true
+		if banner.Enabled != nil {
+			enabled = *banner.Enabled
+		}
+
+		result = append(result, map[string]interface{}{
+			"text":             banner.Text,
+			"enabled":          enabled,
+			"background_color": banner.BackgroundColor,
+			"text_color":       banner.TextColor,
+		})
+	}
+
+	return result
+}
+
+func flattenPermissions(perms *permissions) []interface{} {
+	if perms == nil || (len(perms.Read) == 0 && len(perms.Write) == 0 && len(perms.Execute) == 0) {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"read":    perms.Read,
+			"write":   perms.Write,
+			"execute": perms.Execute,
+		},
+	}
+}
+
+func expandStringSet(set *schema.Set) []string {
+	result := make([]string, 0, set.Len())
+	for _, item := range set.List() {
+		result = append(result, item.(string))
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -7,10 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/internal/fakegate"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAccSpinnakerApplication_basic(t *testing.T) {
@@ -77,3 +81,288 @@ resource "spinnaker_application" "test" {
 }
 `, rName)
 }
+
+func TestSplitCommaSeparated(t *testing.T) {
+	require.Nil(t, splitCommaSeparated(nil))
+	require.Equal(t, []string{"aws", "kubernetes"}, splitCommaSeparated("aws, kubernetes,"))
+	require.Equal(t, []string{"aws", "kubernetes"}, splitCommaSeparated([]interface{}{"aws", "kubernetes"}))
+}
+
+func TestReadApplication(t *testing.T) {
+	payload := map[string]interface{}{
+		"name": "myapp",
+		"attributes": map[string]interface{}{
+			"email":          "team@example.com",
+			"repoType":       "github",
+			"repoSlug":       "myapp",
+			"repoProjectKey": "myorg",
+			"description":    "my application",
+			"cloudProviders": "kubernetes,aws",
+			"instancePort":   float64(8080),
+			"aliases":        "foo,bar",
+			"trafficGuards": []interface{}{
+				map[string]interface{}{"account": "prod", "location": "us-east-1", "enabled": true},
+			},
+			"dataSources": map[string]interface{}{
+				"enabled":  []interface{}{"securityGroups"},
+				"disabled": []interface{}{"loadBalancers"},
+			},
+			"permissions": map[string]interface{}{
+				"READ":    []interface{}{"devs", "ops"},
+				"WRITE":   []interface{}{"ops"},
+				"EXECUTE": []interface{}{"ops"},
+			},
+			"customBanners": []interface{}{
+				map[string]interface{}{"text": "hello", "enabled": true, "backgroundColor": "var(--color-alert)"},
+			},
+		},
+	}
+
+	var app applicationRead
+	require.NoError(t, mapstructure.Decode(payload, &app))
+
+	data := resourceApplication().TestResourceData()
+	require.NoError(t, readApplication(data, app))
+
+	require.Equal(t, "myapp", data.Id())
+	require.Equal(t, "team@example.com", data.Get("email"))
+	require.Equal(t, "github", data.Get("repo_type"))
+	require.Equal(t, "myapp", data.Get("repo_slug"))
+	require.Equal(t, "myorg", data.Get("repo_project_key"))
+	require.Equal(t, "my application", data.Get("description"))
+	require.Equal(t, []interface{}{"kubernetes", "aws"}, data.Get("cloud_providers"))
+	require.Equal(t, 8080, data.Get("instance_port"))
+	require.Equal(t, []interface{}{"foo", "bar"}, data.Get("aliases"))
+	require.Equal(t, "prod", data.Get("traffic_guards.0.account"))
+	require.Equal(t, true, data.Get("traffic_guards.0.enabled"))
+	require.Equal(t, []interface{}{"securityGroups"}, data.Get("data_sources.0.enabled"))
+	require.Equal(t, []interface{}{"loadBalancers"}, data.Get("data_sources.0.disabled"))
+	require.Equal(t, "hello", data.Get("custom_banners.0.text"))
+	require.Equal(t, "var(--color-alert)", data.Get("custom_banners.0.background_color"))
+	require.ElementsMatch(t, []interface{}{"devs", "ops"}, data.Get("permissions.0.read").(*schema.Set).List())
+	require.ElementsMatch(t, []interface{}{"ops"}, data.Get("permissions.0.write").(*schema.Set).List())
+	require.ElementsMatch(t, []interface{}{"ops"}, data.Get("permissions.0.execute").(*schema.Set).List())
+}
+
+func TestReadApplicationDefaults(t *testing.T) {
+	payload := map[string]interface{}{
+		"name": "myapp",
+		"attributes": map[string]interface{}{
+			"email": "team@example.com",
+			"trafficGuards": []interface{}{
+				map[string]interface{}{"account": "prod", "location": "us-east-1"},
+				map[string]interface{}{"account": "prod", "location": "us-west-2", "enabled": false},
+			},
+			"customBanners": []interface{}{
+				map[string]interface{}{"text": "Maintenance"},
+				map[string]interface{}{"text": "Freeze", "enabled": false},
+			},
+		},
+	}
+
+	var app applicationRead
+	require.NoError(t, mapstructure.Decode(payload, &app))
+
+	data := resourceApplication().TestResourceData()
+	require.NoError(t, readApplication(data, app))
+
+	// Attributes missing in applications created in Deck read as their
+	// defaults instead of zero values.
+	require.Equal(t, 80, data.Get("instance_port"))
+	require.Equal(t, true, data.Get("traffic_guards.0.enabled"))
+	require.Equal(t, false, data.Get("traffic_guards.1.enabled"))
+	require.Equal(t, true, data.Get("custom_banners.0.enabled"))
+	require.Equal(t, false, data.Get("custom_banners.1.enabled"))
+}
+
+func TestValidateApplicationPermissions(t *testing.T) {
+	require.NoError(t, validateApplicationPermissions(nil, nil))
+	require.NoError(t, validateApplicationPermissions([]string{"devs", "ops"}, []string{"ops"}))
+	require.EqualError(t,
+		validateApplicationPermissions([]string{"devs"}, []string{"devs", "ops"}),
+		`permissions: roles ["ops"] are granted EXECUTE without READ`)
+}
+
+func TestResourceApplicationCustomizeDiff(t *testing.T) {
+	ctx := context.Background()
+	r := resourceApplication()
+
+	config := func(read interface{}) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"application": "myapp",
+			"email":       "team@example.com",
+			"permissions": []interface{}{
+				map[string]interface{}{
+					"read":    read,
+					"execute": []interface{}{"ops"},
+				},
+			},
+		})
+	}
+
+	_, err := r.Diff(ctx, nil, config([]interface{}{"devs"}), nil)
+	require.EqualError(t, err, `permissions: roles ["ops"] are granted EXECUTE without READ`)
+
+	// Roles read from other resources are only validated once known. The
+	// placeholder is the value terraform uses for unknown values.
+	_, err = r.Diff(ctx, nil, config("74D93920-ED26-11E3-AC10-0800200C9A66"), nil)
+	require.NoError(t, err)
+
+	_, err = r.Diff(ctx, nil, config([]interface{}{"devs", "74D93920-ED26-11E3-AC10-0800200C9A66"}), nil)
+	require.NoError(t, err)
+}
+
+func TestResourceApplication_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+
+	resourceName := "spinnaker_application.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_application" "test" {
+	application     = "myapp"
+	email           = "team@example.com"
+	cloud_providers = ["kubernetes"]
+
+	permissions {
+		read    = ["devs", "ops"]
+		write   = ["ops"]
+		execute = ["ops"]
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "application", "myapp"),
+					resource.TestCheckResourceAttr(resourceName, "email", "team@example.com"),
+					resource.TestCheckResourceAttr(resourceName, "permissions.0.read.#", "2"),
+				),
+			},
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_application" "test" {
+	application     = "myapp"
+	email           = "ops@example.com"
+	cloud_providers = ["kubernetes"]
+
+	permissions {
+		read    = ["devs", "ops"]
+		write   = ["ops"]
+		execute = ["ops"]
+	}
+}
+`,
+				Check: resource.TestCheckResourceAttr(resourceName, "email", "ops@example.com"),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceApplicationCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourceApplication()
+
+	raw := map[string]interface{}{
+		"application":     "myapp",
+		"email":           "team@example.com",
+		"cloud_providers": []interface{}{"kubernetes", "aws"},
+		"permissions": []interface{}{
+			map[string]interface{}{
+				"read":    []interface{}{"devs", "ops"},
+				"write":   []interface{}{"ops"},
+				"execute": []interface{}{"ops"},
+			},
+		},
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.Equal(t, "myapp", data.Id())
+
+	app, ok := srv.Application("myapp")
+	require.True(t, ok)
+	require.Equal(t, "team@example.com", app["email"])
+	require.Equal(t, "kubernetes,aws", app["cloudProviders"])
+	require.Equal(t, map[string]interface{}{
+		"READ":    []interface{}{"devs", "ops"},
+		"WRITE":   []interface{}{"ops"},
+		"EXECUTE": []interface{}{"ops"},
+	}, app["permissions"])
+
+	raw["email"] = "ops@example.com"
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	app, _ = srv.Application("myapp")
+	require.Equal(t, "ops@example.com", app["email"])
+	require.Equal(t, "kubernetes,aws", app["cloudProviders"])
+
+	// Only the changed attributes are submitted, so attributes managed
+	// outside of terraform are kept.
+	jobs := srv.Jobs("updateApplication")
+	require.Len(t, jobs, 1)
+	require.Equal(t, map[string]interface{}{
+		"name":  "myapp",
+		"email": "ops@example.com",
+	}, jobs[0]["application"])
+
+	// Renaming creates a new application instead of updating one that does
+	// not exist yet.
+	rename := map[string]interface{}{"application": "renamed", "email": "ops@example.com"}
+	diff, err := r.Diff(ctx, data.State(), terraform.NewResourceConfigRaw(rename), meta)
+	require.NoError(t, err)
+	require.True(t, diff.RequiresNew())
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Application("myapp")
+	require.False(t, ok)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Empty(t, data.Id())
+}
+
+func TestResourceApplicationCreateTaskFailure(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.FailTasks("createApplication", "Application name is invalid")
+
+	r := resourceApplication()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"email":       "team@example.com",
+	})
+
+	diags := r.CreateContext(context.Background(), data, newTestClientConfig(srv))
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, "Application name is invalid")
+}
+
+func TestResourceApplicationReadRetriesServerErrors(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutApplication("myapp", map[string]interface{}{"email": "team@example.com"})
+	srv.InjectFault(fakegate.Fault{
+		Method:     http.MethodGet,
+		Path:       "/applications/myapp",
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      2,
+	})
+
+	r := resourceApplication()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+	})
+
+	require.False(t, r.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+	require.Equal(t, "team@example.com", data.Get("email"))
+}
@@ -1,13 +1,16 @@
 package spinnaker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
 	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
 )
 
 func resourcePipeline() *schema.Resource {
@@ -15,12 +18,10 @@ func resourcePipeline() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"application": {
 				Type:     schema.TypeString,
-				ForceNew: true,
 				Required: true,
 			},
 			"name": {
 				Type:     schema.TypeString,
-				ForceNew: true,
 				Required: true,
 			},
 			"pipeline": {
@@ -28,16 +29,38 @@ func resourcePipeline() *schema.Resource {
 				Required:         true,
 				DiffSuppressFunc: pipelineDiffSuppressFunc,
 			},
+			"ignore_pipeline_keys": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"pipeline_id": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"fail_on_manual_edit": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"last_modified_by": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"update_ts": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
-		Create: resourcePipelineCreate,
-		Read:   resourcePipelineRead,
-		Update: resourcePipelineUpdate,
-		Delete: resourcePipelineDelete,
-		Exists: resourcePipelineExists,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineImport,
+		},
+		CreateContext: resourcePipelineCreate,
+		ReadContext:   resourcePipelineRead,
+		UpdateContext: resourcePipelineUpdate,
+		DeleteContext: resourcePipelineDelete,
 	}
 }
 
@@ -47,46 +70,175 @@ type pipelineRead struct {
 	ID          string `json:"id"`
 }
 
-func resourcePipelineCreate(data *schema.ResourceData, meta interface{}) error {
+// resourcePipelineImport imports a pipeline either by its UUID or by an ID
+// of the form <application>/<pipeline name>. The application and name are
+// set from the pipeline found, and the resource ID is always the UUID.
+func resourcePipelineImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
+		return nil, err
+	}
+
+	var p pipelineRead
+
+	if applicationName, pipelineName, ok := strings.Cut(data.Id(), "/"); ok {
+		if _, err := api.GetPipeline(ctx, client, applicationName, pipelineName, &p); err != nil {
+			return nil, fmt.Errorf("failed to fetch pipeline %q for application %q: %w",
+				pipelineName, applicationName, err)
+		}
+	} else if _, err := api.GetPipelineByID(ctx, client, data.Id(), &p); err != nil {
+		return nil, fmt.Errorf("failed to fetch pipeline with id %q: %w", data.Id(), err)
+	}
+
+	if err := data.Set("application", p.Application); err != nil {
+		return nil, err
+	}
+
+	if err := data.Set("name", p.Name); err != nil {
+		return nil, err
+	}
+
+	data.SetId(p.ID)
+
+	return []*schema.ResourceData{data}, nil
+}
+
+// createPipeline creates the pipeline. If a pipeline with the same name
+// already exists in the application, it is updated in place instead of being
+// recreated, as a new pipeline ID would break triggers referencing it.
+func createPipeline(ctx context.Context, client *gate.GatewayClient, applicationName, pipelineName string, pipeline map[string]interface{}) error {
+	err := api.CreatePipeline(ctx, client, pipeline)
+	if !apierrors.IsPipelineAlreadyExists(err) {
 		return err
 	}
 
+	var p pipelineRead
+
+	if _, err := api.GetPipeline(ctx, client, applicationName, pipelineName, &p); err != nil {
+		return err
+	}
+
+	pipeline["id"] = p.ID
+
+	return api.UpdatePipeline(ctx, client, p.ID, pipeline)
+}
+
+// getPipeline fetches the pipeline tracked by data. Once the resource has an
+// ID, the pipeline is fetched by ID so that it is still found after being
+// renamed or moved to another application outside of terraform.
+func getPipeline(ctx context.Context, client *gate.GatewayClient, data *schema.ResourceData, dest *pipelineRead) (map[string]interface{}, error) {
+	if data.Id() == "" {
+		return api.GetPipeline(ctx, client, data.Get("application").(string), data.Get("name").(string), dest)
+	}
+
+	return getPipelineByID(ctx, client, data.Id(), dest)
+}
+
+// getPipelineByID fetches the current config of the pipeline with pipelineID.
+// Returns an error that satisfies errors.IsNotFound if the pipeline does not
+// exist.
+func getPipelineByID(ctx context.Context, client *gate.GatewayClient, pipelineID string, dest *pipelineRead) (map[string]interface{}, error) {
+	if _, err := api.GetPipelineByID(ctx, client, pipelineID, dest); err != nil {
+		return nil, err
+	}
+
+	// The history of a pipeline outlives the pipeline with some Front50
+	// storage backends, so make sure the pipeline is still listed for its
+	// application.
+	var pipelines []pipelineRead
+
+	payload, err := api.ListPipelines(ctx, client, dest.Application, &pipelines)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range pipelines {
+		if p.ID == pipelineID {
+			*dest = p
+			return payload[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("pipeline with id %q %w", pipelineID, apierrors.ErrNotFound)
+}
+
+func resourcePipelineCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	applicationName := data.Get("application").(string)
 	pipelineName := data.Get("name").(string)
 	rawPipeline := data.Get("pipeline").(string)
 
 	pipeline, err := parsePipeline(rawPipeline)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	pipeline["application"] = applicationName
 	pipeline["name"] = pipelineName
 	delete(pipeline, "id")
 
-	err = api.CreatePipeline(client, pipeline)
-	if apierrors.IsPipelineAlreadyExists(err) {
-		err = api.RecreatePipeline(client, applicationName, pipelineName, pipeline)
+	if err := createPipeline(ctx, client, applicationName, pipelineName, pipeline); err != nil {
+		return diag.Errorf("failed to create pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	return resourcePipelineRead(ctx, data, meta)
+}
+
+func resourcePipelineRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	priorUpdateTs := data.Get("update_ts").(string)
+
+	if diags := readPipeline(ctx, data, meta); diags.HasError() || data.Id() == "" {
+		return diags
 	}
 
+	// Only warn about revisions that are new since the last refresh, so that
+	// the warning stops once the state is saved.
+	if priorUpdateTs == "" || priorUpdateTs == data.Get("update_ts").(string) {
+		return nil
+	}
+
+	clientConfig := meta.(*clientConfig)
+	lastModifiedBy := data.Get("last_modified_by").(string)
+
+	user, err := clientConfig.CurrentUser(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create pipeline %q for application %q: %w",
-			pipelineName, applicationName, err)
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Skipped check for manual edits of the pipeline",
+			Detail:   fmt.Sprintf("Failed to fetch the user the provider authenticates as: %s", err),
+		}}
 	}
 
-	return resourcePipelineRead(data, meta)
+	if user == lastModifiedBy {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary: fmt.Sprintf("Pipeline %q of application %q was modified outside of terraform",
+			data.Get("name").(string), data.Get("application").(string)),
+		Detail: fmt.Sprintf("The pipeline was last modified by %q, but the provider authenticates as %q. "+
+			"Applying changes to the pipeline will overwrite these modifications.", lastModifiedBy, user),
+	}}
 }
 
-func resourcePipelineRead(data *schema.ResourceData, meta interface{}) error {
+// readPipeline reads the pipeline into data. The ID of data is cleared if the
+// pipeline does not exist.
+func readPipeline(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	applicationName := data.Get("application").(string)
@@ -94,26 +246,60 @@ func resourcePipelineRead(data *schema.ResourceData, meta interface{}) error {
 
 	var p pipelineRead
 
-	pipeline, err := api.GetPipeline(client, applicationName, pipelineName, &p)
-	if apierrors.IsNotFound(err) {
+	// Weird error case: sometimes spinnaker returns an EOF error for
+	// non-existing pipelines when they are looked up by name. Lookups by ID
+	// report missing pipelines as not found.
+	byName := data.Id() == ""
+
+	pipeline, err := getPipeline(ctx, client, data, &p)
+	if apierrors.IsNotFound(err) || (byName && err != nil && strings.Contains(err.Error(), "EOF")) {
 		data.SetId("")
 		return nil
 	} else if err != nil {
-		return fmt.Errorf("failed to fetch pipeline %q for application %q: %w",
+		return diag.Errorf("failed to fetch pipeline %q for application %q: %s",
 			pipelineName, applicationName, err)
 	}
 
+	// The keys are removed from the pipeline when it is encoded.
+	lastModifiedBy := stringValue(pipeline["lastModifiedBy"])
+	updateTs := stringValue(pipeline["updateTs"])
+
+	// Changes Spinnaker made to ignored keys are discarded by keeping the
+	// values of the prior state.
+	var prior map[string]interface{}
+	if rawPrior, ok := data.GetOk("pipeline"); ok {
+		prior, _ = parsePipeline(rawPrior.(string))
+	}
+
+	ignorePipelineKeys(pipeline, prior, pipelineIgnoredKeys(data, clientConfig))
+
 	encodedPipeline, err := editAndEncodePipeline(pipeline)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("pipeline", encodedPipeline); err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("application", p.Application); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("name", p.Name); err != nil {
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("pipeline_id", p.ID); err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("last_modified_by", lastModifiedBy); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("update_ts", updateTs); err != nil {
+		return diag.FromErr(err)
 	}
 
 	data.SetId(p.ID)
@@ -121,12 +307,12 @@ func resourcePipelineRead(data *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func resourcePipelineUpdate(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	applicationName := data.Get("application").(string)
@@ -136,85 +322,84 @@ func resourcePipelineUpdate(data *schema.ResourceData, meta interface{}) error {
 
 	pipeline, err := parsePipeline(rawPipeline)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	pipeline["application"] = applicationName
 	pipeline["name"] = pipelineName
 	pipeline["id"] = pipelineID
 
-	err = api.UpdatePipeline(client, pipelineID, pipeline)
-	if apierrors.IsPipelineAlreadyExists(err) {
-		// Although it seems odd, this error can happen here due to the hideous
-		// spinnaker API. We handle it by just recreating the pipeline.
-		err = api.RecreatePipeline(client, applicationName, pipelineName, pipeline)
+	if data.Get("fail_on_manual_edit").(bool) {
+		if err := checkManualPipelineEdit(ctx, client, clientConfig, pipelineID); err != nil {
+			return diag.Errorf("refusing to update pipeline %q for application %q: %s",
+				pipelineName, applicationName, err)
+		}
 	}
 
-	if err != nil {
-		return fmt.Errorf("failed to update pipeline %q for application %q: %w",
+	if err := api.UpdatePipeline(ctx, client, pipelineID, pipeline); err != nil {
+		return diag.Errorf("failed to update pipeline %q for application %q: %s",
 			pipelineName, applicationName, err)
 	}
 
-	return resourcePipelineRead(data, meta)
+	return resourcePipelineRead(ctx, data, meta)
 }
 
-func resourcePipelineDelete(data *schema.ResourceData, meta interface{}) error {
-	clientConfig := meta.(*clientConfig)
+// checkManualPipelineEdit returns an error if the current revision of the
+// pipeline with pipelineID was saved by a user other than the one the
+// provider authenticates as.
+func checkManualPipelineEdit(ctx context.Context, client *gate.GatewayClient, clientConfig *clientConfig, pipelineID string) error {
+	user, err := clientConfig.CurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch current user: %w", err)
+	}
 
-	client, err := clientConfig.Client()
+	var p pipelineRead
+
+	pipeline, err := api.GetPipelineByID(ctx, client, pipelineID, &p)
 	if err != nil {
 		return err
 	}
 
-	applicationName := data.Get("application").(string)
-	pipelineName := data.Get("name").(string)
-
-	err = api.DeletePipeline(client, applicationName, pipelineName)
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete pipeline %q for application %q: %w",
-			pipelineName, applicationName, err)
+	if lastModifiedBy := stringValue(pipeline["lastModifiedBy"]); lastModifiedBy != user {
+		return fmt.Errorf("it was last modified by %q instead of %q, set fail_on_manual_edit to false to overwrite the modifications",
+			lastModifiedBy, user)
 	}
 
 	return nil
 }
 
-func resourcePipelineExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+func resourcePipelineDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return false, err
+		return diag.FromErr(err)
 	}
 
 	applicationName := data.Get("application").(string)
 	pipelineName := data.Get("name").(string)
 
-	var p pipelineRead
-
-	_, err = api.GetPipeline(client, applicationName, pipelineName, &p)
-	if err != nil {
-		// Weird error case: sometimes spinnaker returns an EOF error for non-existing pipelines.
-		if apierrors.IsNotFound(err) || strings.Contains(err.Error(), "EOF") {
-			return false, nil
-		}
-
-		return false, fmt.Errorf("failed to fetch pipeline %q for application %q: %w",
+	err = api.DeletePipeline(ctx, client, applicationName, pipelineName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return diag.Errorf("failed to delete pipeline %q for application %q: %s",
 			pipelineName, applicationName, err)
 	}
 
-	return true, nil
+	return nil
 }
 
 func pipelineDiffSuppressFunc(k, old, new string, d *schema.ResourceData) bool {
 	// Spinnaker does non-trivial modifications to the JSON for a pipeline,
 	// so we round-trip decode, edit, and encode the user's pipeline
 	// spec, and compare against the decoded, edited, and encoded new pipeline.
-	editedOld, err := decodeEditAndEncodePipeline(old)
+	ignoredKeys := pipelineIgnoredKeys(d, nil)
+
+	editedOld, err := decodeEditAndEncodePipeline(old, ignoredKeys)
 	if err != nil {
 		return false
 	}
 
-	editedNew, err := decodeEditAndEncodePipeline(new)
+	editedNew, err := decodeEditAndEncodePipeline(new, ignoredKeys)
 	if err != nil {
 		return false
 	}
@@ -232,25 +417,39 @@ func parsePipeline(rawPipeline string) (map[string]interface{}, error) {
 	return pipeline, nil
 }
 
-func decodeEditAndEncodePipeline(rawPipeline string) (string, error) {
+func decodeEditAndEncodePipeline(rawPipeline string, ignoredKeys []string) (string, error) {
 	pipeline, err := parsePipeline(rawPipeline)
 	if err != nil {
 		return "", err
 	}
 
+	ignorePipelineKeys(pipeline, nil, ignoredKeys)
+
 	return editAndEncodePipeline(pipeline)
 }
 
+// pipelineIgnoredKeys returns the pipeline keys ignored by the resource and,
+// if clientConfig is not nil, by the provider.
+func pipelineIgnoredKeys(data *schema.ResourceData, clientConfig *clientConfig) []string {
+	var keys []string
+
+	if clientConfig != nil {
+		keys = append(keys, clientConfig.ignorePipelineKeys...)
+	}
+
+	if v, ok := data.GetOk("ignore_pipeline_keys"); ok {
+		for _, key := range v.([]interface{}) {
+			keys = append(keys, key.(string))
+		}
+	}
+
+	return keys
+}
+
 func editAndEncodePipeline(pipeline map[string]interface{}) (string, error) {
-	// Remove the keys we know are problematic because they are managed
-	// by spinnaker or are handled by other schema attributes.
-	delete(pipeline, "application")
-	delete(pipeline, "lastModifiedBy")
-	delete(pipeline, "id")
-	delete(pipeline, "index")
-	delete(pipeline, "name")
-	delete(pipeline, "schema")
-	delete(pipeline, "updateTs")
+	// Remove the keys that are managed by spinnaker, handled by other schema
+	// attributes or set to the values spinnaker uses as defaults.
+	normalizePipeline(pipeline)
 
 	encoded, err := json.Marshal(pipeline)
 	if err != nil {
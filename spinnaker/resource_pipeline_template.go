@@ -2,6 +2,7 @@ package spinnaker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
 	"github.com/ghodss/yaml"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -25,24 +27,22 @@ func resourcePipelineTemplate() *schema.Resource {
 				Computed: true,
 			},
 		},
-		Create: resourcePipelineTemplateCreate,
-		Read:   resourcePipelineTemplateRead,
-		Update: resourcePipelineTemplateUpdate,
-		Delete: resourcePipelineTemplateDelete,
-		Exists: resourcePipelineTemplateExists,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CreateContext: resourcePipelineTemplateCreate,
+		ReadContext:   resourcePipelineTemplateRead,
+		UpdateContext: resourcePipelineTemplateUpdate,
+		DeleteContext: resourcePipelineTemplateDelete,
 	}
 }
 
-type templateRead struct {
-	ID string `json:"id"`
-}
-
-func resourcePipelineTemplateCreate(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var templateName string
@@ -50,48 +50,48 @@ func resourcePipelineTemplateCreate(data *schema.ResourceData, meta interface{})
 
 	d, err := yaml.YAMLToJSON([]byte(template))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var jsonContent map[string]interface{}
 	if err = json.NewDecoder(bytes.NewReader(d)).Decode(&jsonContent); err != nil {
-		return fmt.Errorf("Error decoding json: %s", err.Error())
+		return diag.Errorf("Error decoding json: %s", err.Error())
 	}
 
 	if _, ok := jsonContent["schema"]; !ok {
-		return fmt.Errorf("Pipeline save command currently only supports pipeline template configurations")
+		return diag.Errorf("Pipeline save command currently only supports pipeline template configurations")
 	}
 
 	templateName = jsonContent["id"].(string)
 
 	log.Println("[DEBUG] Making request to spinnaker")
-	if err := api.CreatePipelineTemplate(client, jsonContent); err != nil {
+	if err := api.CreatePipelineTemplate(ctx, client, jsonContent); err != nil {
 		log.Printf("[DEBUG] Error response from spinnaker: %s", err.Error())
-		return err
+		return diag.FromErr(err)
 	}
 
 	log.Printf("[DEBUG] Created template successfully")
 	data.SetId(templateName)
-	return resourcePipelineTemplateRead(data, meta)
+	return resourcePipelineTemplateRead(ctx, data, meta)
 }
 
-func resourcePipelineTemplateRead(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	templateName := data.Id()
 
 	t := make(map[string]interface{})
-	if err := api.GetPipelineTemplate(client, templateName, &t); err != nil {
+	if err := api.GetPipelineTemplate(ctx, client, templateName, &t); err != nil {
 		if err.Error() == api.ErrCodeNoSuchEntityException {
 			data.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	// Remove timestamp from response
@@ -100,23 +100,19 @@ func resourcePipelineTemplateRead(data *schema.ResourceData, meta interface{}) e
 
 	jsonContent, err := json.Marshal(t)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	raw, err := yaml.JSONToYAML(jsonContent)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	if err := data.Set("name", t["id"].(string)); err != nil {
-		return err
-	}
-
 	if err := data.Set("template", string(raw)); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("url", fmt.Sprintf("spinnaker://%s", t["id"].(string))); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	data.SetId(t["id"].(string))
@@ -124,12 +120,12 @@ func resourcePipelineTemplateRead(data *schema.ResourceData, meta interface{}) e
 	return nil
 }
 
-func resourcePipelineTemplateUpdate(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var templateName string
@@ -137,71 +133,46 @@ func resourcePipelineTemplateUpdate(data *schema.ResourceData, meta interface{})
 
 	d, err := yaml.YAMLToJSON([]byte(template))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var jsonContent map[string]interface{}
 	if err = json.NewDecoder(bytes.NewReader(d)).Decode(&jsonContent); err != nil {
-		return fmt.Errorf("Error decoding json: %s", err.Error())
+		return diag.Errorf("Error decoding json: %s", err.Error())
 	}
 
 	if _, ok := jsonContent["schema"]; !ok {
-		return fmt.Errorf("Pipeline save command currently only supports pipeline template configurations")
+		return diag.Errorf("Pipeline save command currently only supports pipeline template configurations")
 	}
 
 	templateName = jsonContent["id"].(string)
 
-	if err := api.UpdatePipelineTemplate(client, templateName, jsonContent); err != nil {
-		return err
+	if err := api.UpdatePipelineTemplate(ctx, client, templateName, jsonContent); err != nil {
+		return diag.FromErr(err)
 	}
 
 	data.SetId(templateName)
-	return resourcePipelineTemplateRead(data, meta)
+	return resourcePipelineTemplateRead(ctx, data, meta)
 }
 
-func resourcePipelineTemplateDelete(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	templateName := data.Id()
 
-	if err := api.DeletePipelineTemplate(client, templateName); err != nil {
-		return err
+	if err := api.DeletePipelineTemplate(ctx, client, templateName); err != nil {
+		return diag.FromErr(err)
 	}
 
 	data.SetId("")
 	return nil
 }
 
-func resourcePipelineTemplateExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	clientConfig := meta.(*clientConfig)
-
-	client, err := clientConfig.Client()
-	if err != nil {
-		return false, err
-	}
-
-	templateName := data.Id()
-
-	t := &templateRead{}
-	if err := api.GetPipelineTemplate(client, templateName, t); err != nil {
-		if err.Error() == api.ErrCodeNoSuchEntityException {
-			return false, nil
-		}
-		return false, err
-	}
-
-	if t.ID == templateName {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func suppressEquivalentPipelineTemplateDiffs(k, old, new string, d *schema.ResourceData) bool {
 	equivalent, err := areEqualJSON(old, new)
 	if err != nil {
@@ -2,12 +2,16 @@ package spinnaker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
 	"github.com/ghodss/yaml"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -65,164 +69,160 @@ func resourcePipelineTemplateConfig() *schema.Resource {
 				Default:  false,
 			},
 		},
-		Create: resourcePipelineTemplateConfigCreate,
-		Read:   resourcePipelineTemplateConfigRead,
-		Update: resourcePipelineTemplateConfigUpdate,
-		Delete: resourcePipelineTemplateConfigDelete,
-		Exists: resourcePipelineTemplateConfigExists,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineTemplateConfigImport,
+		},
+		CreateContext: resourcePipelineTemplateConfigCreate,
+		ReadContext:   resourcePipelineTemplateConfigRead,
+		UpdateContext: resourcePipelineTemplateConfigUpdate,
+		DeleteContext: resourcePipelineTemplateConfigDelete,
 	}
 }
 
-func resourcePipelineTemplateConfigCreate(data *schema.ResourceData, meta interface{}) error {
+// resourcePipelineTemplateConfigImport imports a templated pipeline by an ID
+// of the form <application>/<pipeline name>.
+func resourcePipelineTemplateConfigImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	application, name, ok := strings.Cut(data.Id(), "/")
+	if !ok || application == "" || name == "" {
+		return nil, fmt.Errorf("invalid import ID %q, expected <application>/<pipeline name>", data.Id())
+	}
+
+	if err := data.Set("application", application); err != nil {
+		return nil, err
+	}
+
+	if err := data.Set("name", name); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{data}, nil
+}
+
+func resourcePipelineTemplateConfigCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	pConfig, err := buildConfig(data)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	log.Println("[DEBUG] Making request to spinnaker")
-	if err := api.CreatePipeline(client, *pConfig); err != nil {
+	if err := api.CreatePipeline(ctx, client, *pConfig); err != nil {
 		log.Printf("[DEBUG] Error response from spinnaker: %s", err.Error())
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("name", pConfig.Name); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("application", pConfig.Application); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourcePipelineTemplateConfigRead(data, meta)
+	return resourcePipelineTemplateConfigRead(ctx, data, meta)
 }
 
-func resourcePipelineTemplateConfigRead(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateConfigRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	application := data.Get("application").(string)
 	name := data.Get("name").(string)
 
 	p := PipelineConfig{}
-	if _, err := api.GetPipeline(client, application, name, &p); err != nil {
-		if err.Error() == api.ErrCodeNoSuchEntityException {
+	if _, err := api.GetPipeline(ctx, client, application, name, &p); err != nil {
+		if apierrors.IsNotFound(err) {
 			data.SetId("")
 			return nil
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	raw, err := yaml.Marshal(p.Config)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("name", p.Name); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("application", p.Application); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("parallel", p.Parallel); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("keep_waiting", p.KeepWaitingPipelines); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	if err := data.Set("limit_concurrent", p.LimitConcurrent); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	if err := data.Set("pipeline_config", raw); err != nil {
-		return err
+	if err := data.Set("pipeline_config", string(raw)); err != nil {
+		return diag.FromErr(err)
 	}
 
 	data.SetId(p.ID)
 	return nil
 }
 
-func resourcePipelineTemplateConfigUpdate(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateConfigUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	pipelineID := data.Id()
 
 	pConfig, err := buildConfig(data)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	pConfig.ID = pipelineID
-	if err := api.UpdatePipeline(client, pipelineID, *pConfig); err != nil {
-		return err
+	if err := api.UpdatePipeline(ctx, client, pipelineID, *pConfig); err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourcePipelineTemplateConfigRead(data, meta)
+	return resourcePipelineTemplateConfigRead(ctx, data, meta)
 }
 
-func resourcePipelineTemplateConfigDelete(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateConfigDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	application := data.Get("application").(string)
 	name := data.Get("name").(string)
 
-	if err := api.DeletePipeline(client, application, name); err != nil {
-		return err
+	if err := api.DeletePipeline(ctx, client, application, name); err != nil {
+		return diag.FromErr(err)
 	}
 
 	data.SetId("")
 	return nil
 }
 
-func resourcePipelineTemplateConfigExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	clientConfig := meta.(*clientConfig)
-
-	client, err := clientConfig.Client()
-	if err != nil {
-		return false, err
-	}
-
-	templateName := data.Id()
-
-	var t templateRead
-	if err := api.GetPipelineTemplate(client, templateName, &t); err != nil {
-		if err.Error() == api.ErrCodeNoSuchEntityException {
-			return false, nil
-		}
-		return false, err
-	}
-
-	if t.ID == templateName {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func buildConfig(data *schema.ResourceData) (*PipelineConfig, error) {
 	config := data.Get("pipeline_config").(string)
 
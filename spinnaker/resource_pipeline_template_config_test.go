@@ -0,0 +1,54 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipelineTemplateConfig = `schema: "1"
+pipeline:
+  application: myapp
+  name: deploy
+  template:
+    source: spinnaker://my-template
+configuration:
+  description: Deploys myapp
+`
+
+func TestResourcePipelineTemplateConfigCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipelineTemplateConfig()
+
+	raw := map[string]interface{}{
+		"pipeline_config": testPipelineTemplateConfig,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.Equal(t, "myapp", data.Get("application"))
+	require.Equal(t, "deploy", data.Get("name"))
+
+	pipeline, ok := srv.Pipeline("myapp", "deploy")
+	require.True(t, ok)
+	require.Equal(t, pipeline["id"], data.Id())
+	require.Equal(t, "templatedPipeline", pipeline["type"])
+	require.Equal(t, "Deploys myapp", pipeline["description"])
+	require.Equal(t, true, pipeline["limitConcurrent"])
+
+	raw["limit_concurrent"] = false
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.Equal(t, false, pipeline["limitConcurrent"])
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Pipeline("myapp", "deploy")
+	require.False(t, ok)
+}
@@ -0,0 +1,78 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipelineTemplate = `schema: "1"
+id: my-template
+metadata:
+  name: My template
+  description: Deploys things
+  scopes:
+  - global
+stages: []
+`
+
+func TestResourcePipelineTemplate_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+
+	resourceName := "spinnaker_pipeline_template.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_pipeline_template" "test" {
+	template = <<-EOT
+` + testPipelineTemplate + `
+	EOT
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", "my-template"),
+					resource.TestCheckResourceAttr(resourceName, "url", "spinnaker://my-template"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourcePipelineTemplateCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipelineTemplate()
+
+	raw := map[string]interface{}{
+		"template": testPipelineTemplate,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.Equal(t, "my-template", data.Id())
+	require.Equal(t, "spinnaker://my-template", data.Get("url"))
+
+	template, ok := srv.PipelineTemplate("my-template")
+	require.True(t, ok)
+	require.Equal(t, "My template", template["metadata"].(map[string]interface{})["name"])
+
+	raw["template"] = testPipelineTemplate + "variables: []\n"
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	template, _ = srv.PipelineTemplate("my-template")
+	require.Equal(t, []interface{}{}, template["variables"])
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.PipelineTemplate("my-template")
+	require.False(t, ok)
+}
@@ -1,16 +1,23 @@
 package spinnaker
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
 	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// pipelineTemplateV2LatestTag is the tag Front50 stores the most recently
+// saved version of a template under.
+const pipelineTemplateV2LatestTag = "latest"
+
 func resourcePipelineTemplateV2() *schema.Resource {
 	return &schema.Resource{
 		Description: "Provides a V2 pipeline template. See https://spinnaker.io/reference/pipeline/templates/ for more details.",
@@ -36,62 +43,136 @@ func resourcePipelineTemplateV2() *schema.Resource {
 				ForceNew:    true,
 				Required:    true,
 			},
+			"tag": {
+				Description: "Tag to publish the template under, e.g. `stable`. Without a tag, the template is published as the latest version.",
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Optional:    true,
+			},
 			"reference": {
-				Description: "The URL for referencing the template in a pipeline instance.",
+				Description: "The URL for referencing the template in a pipeline instance. Includes the tag if set.",
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			"digest": {
+				Description: "Digest of the published template version.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"versions": {
+				Description: "All published versions of the template.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": {
+							Description: "Tag of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"digest": {
+							Description: "Digest of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
 		},
-		Create: resourcePipelineTemplateV2Create,
-		Read:   resourcePipelineTemplateV2Read,
-		Update: resourcePipelineTemplateV2Update,
-		Delete: resourcePipelineTemplateV2Delete,
-		Exists: resourcePipelineTemplateV2Exists,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineTemplateV2Import,
+		},
+		CreateContext: resourcePipelineTemplateV2Create,
+		ReadContext:   resourcePipelineTemplateV2Read,
+		UpdateContext: resourcePipelineTemplateV2Update,
+		DeleteContext: resourcePipelineTemplateV2Delete,
+	}
+}
+
+// resourcePipelineTemplateV2Import imports a pipeline template by its
+// template ID, or a tagged version of it by <template ID>:<tag>.
+func resourcePipelineTemplateV2Import(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	templateID, tag := parsePipelineTemplateV2ID(data.Id())
+
+	if err := data.Set("template_id", templateID); err != nil {
+		return nil, err
+	}
+
+	if err := data.Set("tag", tag); err != nil {
+		return nil, err
 	}
+
+	return []*schema.ResourceData{data}, nil
 }
 
-func resourcePipelineTemplateV2Create(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateV2Create(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	rawTemplate := data.Get("template").(string)
 
 	template, err := parsePipelineTemplateV2(rawTemplate)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	template.ID = data.Get("template_id").(string)
+	tag := data.Get("tag").(string)
 
-	if err := api.CreatePipelineTemplateV2(client, template); err != nil {
-		return fmt.Errorf("failed to create pipeline template: %w", err)
+	versionMap, err := api.ListPipelineTemplateV2Versions(ctx, client)
+	if err != nil {
+		return diag.Errorf("failed to list pipeline template versions: %s", err)
 	}
 
-	data.SetId(template.ID)
+	versions := versionMap[template.ID]
+
+	// Tagged versions of a template may be managed by different resources,
+	// so publishing a tag adds a version to an existing template. As every
+	// publish also replaces the latest version, a template managed without a
+	// tag cannot have tagged versions and vice versa.
+	switch tags := pipelineTemplateV2Tags(versions); {
+	case len(versions) == 0:
+		err = api.CreatePipelineTemplateV2(ctx, client, template, tag)
+	case tag == "" && len(tags) > 0:
+		return diag.Errorf("pipeline template %q is published with tags %q, set tag to manage a version of it", template.ID, tags)
+	case tag == "":
+		return diag.Errorf("pipeline template %q already exists", template.ID)
+	case len(tags) == 0:
+		return diag.Errorf("pipeline template %q is published without a tag and cannot have tagged versions", template.ID)
+	default:
+		err = api.UpdatePipelineTemplateV2(ctx, client, template, tag)
+	}
 
-	return resourcePipelineTemplateV2Read(data, meta)
+	if err != nil {
+		return diag.Errorf("failed to create pipeline template: %s", err)
+	}
+
+	data.SetId(pipelineTemplateV2ID(template.ID, tag))
+
+	return resourcePipelineTemplateV2Read(ctx, data, meta)
 }
 
-func resourcePipelineTemplateV2Read(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateV2Read(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	templateID := data.Get("template_id").(string)
+	tag := data.Get("tag").(string)
 
-	template, err := api.GetPipelineTemplateV2(client, templateID)
+	template, err := api.GetPipelineTemplateV2(ctx, client, templateID, tag, "")
 	if apierrors.IsNotFound(err) {
 		data.SetId("")
 		return nil
 	} else if err != nil {
-		return fmt.Errorf("failed to fetch pipeline template %q: %w", templateID, err)
+		return diag.Errorf("failed to fetch pipeline template %q: %s", templateID, err)
 	}
 
 	// Unset template ID before marshalling so it does not cause a diff in the
@@ -100,74 +181,100 @@ func resourcePipelineTemplateV2Read(data *schema.ResourceData, meta interface{})
 
 	rawTemplate, err := json.Marshal(template)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	reference := fmt.Sprintf("spinnaker://%s", templateID)
-
-	if err := data.Set("template", string(rawTemplate)); err != nil {
-		return err
+	versionMap, err := api.ListPipelineTemplateV2Versions(ctx, client)
+	if err != nil {
+		return diag.Errorf("failed to list pipeline template versions: %s", err)
 	}
 
-	if err := data.Set("template_id", templateID); err != nil {
-		return err
+	versions := versionMap[templateID]
+
+	attributes := map[string]interface{}{
+		"template":    string(rawTemplate),
+		"template_id": templateID,
+		"tag":         tag,
+		"reference":   pipelineTemplateV2Reference(templateID, tag),
+		"digest":      pipelineTemplateV2Digest(versions, tag),
+		"versions":    flattenPipelineTemplateV2Versions(versions),
 	}
 
-	if err := data.Set("reference", reference); err != nil {
-		return err
+	for key, value := range attributes {
+		if err := data.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	data.SetId(templateID)
+	data.SetId(pipelineTemplateV2ID(templateID, tag))
 
 	return nil
 }
 
-func resourcePipelineTemplateV2Update(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateV2Update(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	rawTemplate := data.Get("template").(string)
 
 	template, err := parsePipelineTemplateV2(rawTemplate)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	template.ID = data.Get("template_id").(string)
+	tag := data.Get("tag").(string)
 
-	if err := api.UpdatePipelineTemplateV2(client, template); err != nil {
-		return fmt.Errorf("failed to update pipeline template %q: %w", template.ID, err)
+	if err := api.UpdatePipelineTemplateV2(ctx, client, template, tag); err != nil {
+		return diag.Errorf("failed to update pipeline template %q: %s", template.ID, err)
 	}
 
-	data.SetId(template.ID)
-
-	return resourcePipelineTemplateV2Read(data, meta)
+	return resourcePipelineTemplateV2Read(ctx, data, meta)
 }
 
-func resourcePipelineTemplateV2Delete(data *schema.ResourceData, meta interface{}) error {
+func resourcePipelineTemplateV2Delete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(*clientConfig)
 
 	client, err := clientConfig.Client()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	templateID := data.Get("template_id").(string)
+	tag := data.Get("tag").(string)
+
+	// A tagged version is only one of possibly many versions managed by
+	// different resources, so only the tag is removed.
+	if tag != "" {
+		err := api.DeletePipelineTemplateV2(ctx, client, templateID, tag, "")
+		if err != nil && !apierrors.IsNotFound(err) {
+			return diag.Errorf("failed to delete pipeline template %q (tag: %q): %s", templateID, tag, err)
+		}
+	}
 
-	versionMap, err := api.ListPipelineTemplateV2Versions(client)
+	versionMap, err := api.ListPipelineTemplateV2Versions(ctx, client)
 	if err != nil {
-		return fmt.Errorf("failed to list pipeline template versions: %w", err)
+		return diag.Errorf("failed to list pipeline template versions: %s", err)
+	}
+
+	versions := versionMap[templateID]
+
+	// The latest version is shared by all tagged versions and removed with
+	// the last tag.
+	if tag != "" && len(pipelineTemplateV2Tags(versions)) > 0 {
+		data.SetId("")
+		return nil
 	}
 
 	// Delete all versions for this pipeline template.
-	for _, version := range versionMap[templateID] {
-		err := api.DeletePipelineTemplateV2(client, version.ID, version.Tag, version.Digest)
+	for _, version := range versions {
+		err := api.DeletePipelineTemplateV2(ctx, client, version.ID, version.Tag, version.Digest)
 		if err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to delete pipeline template %q (tag: %q, digest: %q): %w",
+			return diag.Errorf("failed to delete pipeline template %q (tag: %q, digest: %q): %s",
 				version.ID, version.Tag, version.Digest, err)
 		}
 	}
@@ -177,24 +284,64 @@ func resourcePipelineTemplateV2Delete(data *schema.ResourceData, meta interface{
 	return nil
 }
 
-func resourcePipelineTemplateV2Exists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	clientConfig := meta.(*clientConfig)
+// pipelineTemplateV2ID returns the resource ID of the template version with
+// tag. It is the template ID for untagged templates and <template ID>:<tag>
+// otherwise.
+func pipelineTemplateV2ID(templateID, tag string) string {
+	if tag == "" {
+		return templateID
+	}
 
-	client, err := clientConfig.Client()
-	if err != nil {
-		return false, err
+	return templateID + ":" + tag
+}
+
+// parsePipelineTemplateV2ID is the inverse of pipelineTemplateV2ID.
+func parsePipelineTemplateV2ID(id string) (templateID, tag string) {
+	templateID, tag, _ = strings.Cut(id, ":")
+	return templateID, tag
+}
+
+// pipelineTemplateV2Reference returns the URL pipeline instances use to
+// reference the template version with tag.
+func pipelineTemplateV2Reference(templateID, tag string) string {
+	return "spinnaker://" + pipelineTemplateV2ID(templateID, tag)
+}
+
+// pipelineTemplateV2Digest returns the digest of the version tagged with tag,
+// or of the latest version if tag is empty.
+func pipelineTemplateV2Digest(versions []*api.PipelineTemplateV2Version, tag string) string {
+	for _, version := range versions {
+		if version.Tag == tag || (tag == "" && version.Tag == pipelineTemplateV2LatestTag) {
+			return version.Digest
+		}
 	}
 
-	templateID := data.Get("template_id").(string)
+	return ""
+}
 
-	_, err = api.GetPipelineTemplateV2(client, templateID)
-	if apierrors.IsNotFound(err) {
-		return false, nil
-	} else if err != nil {
-		return false, fmt.Errorf("failed to fetch pipeline template %q: %w", templateID, err)
+// pipelineTemplateV2Tags returns the tags of the versions, except for the tag
+// of the latest version.
+func pipelineTemplateV2Tags(versions []*api.PipelineTemplateV2Version) []string {
+	var tags []string
+	for _, version := range versions {
+		if version.Tag != "" && version.Tag != pipelineTemplateV2LatestTag {
+			tags = append(tags, version.Tag)
+		}
+	}
+
+	return tags
+}
+
+func flattenPipelineTemplateV2Versions(versions []*api.PipelineTemplateV2Version) []interface{} {
+	result := make([]interface{}, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, map[string]interface{}{
+			"tag":    version.Tag,
+			"digest": version.Digest,
+		})
 	}
 
-	return true, nil
+	return result
 }
 
 func parsePipelineTemplateV2(rawTemplate string) (*api.PipelineTemplateV2, error) {
@@ -1,9 +1,13 @@
 package spinnaker
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,3 +46,183 @@ func TestParsePipelineTemplateV2(t *testing.T) {
 		require.Regexp(t, `.*field 'id' must not be set.*`, err.Error())
 	})
 }
+
+func TestPipelineTemplateV2Digest(t *testing.T) {
+	untagged := []*api.PipelineTemplateV2Version{{Tag: "", Digest: "sha256:a"}}
+	require.Equal(t, "sha256:a", pipelineTemplateV2Digest(untagged, ""))
+
+	tagged := []*api.PipelineTemplateV2Version{
+		{Tag: "stable", Digest: "sha256:b"},
+		{Tag: "latest", Digest: "sha256:c"},
+	}
+	require.Equal(t, "sha256:c", pipelineTemplateV2Digest(tagged, ""))
+	require.Equal(t, "sha256:b", pipelineTemplateV2Digest(tagged, "stable"))
+	require.Equal(t, "", pipelineTemplateV2Digest(tagged, "beta"))
+}
+
+func TestResourcePipelineTemplateV2Import(t *testing.T) {
+	data := resourcePipelineTemplateV2().TestResourceData()
+	data.SetId("my-template")
+
+	result, err := resourcePipelineTemplateV2Import(context.Background(), data, nil)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Equal(t, "my-template", result[0].Get("template_id"))
+	require.Empty(t, result[0].Get("tag"))
+
+	data.SetId("my-template:stable")
+
+	result, err = resourcePipelineTemplateV2Import(context.Background(), data, nil)
+	require.NoError(t, err)
+	require.Equal(t, "my-template", result[0].Get("template_id"))
+	require.Equal(t, "stable", result[0].Get("tag"))
+}
+
+const testPipelineTemplateV2 = `{"schema":"v2","pipeline":{"stages":[]},"metadata":{"name":"bar","description":"baz","scopes":["global"]}}`
+
+func TestResourcePipelineTemplateV2_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+
+	resourceName := "spinnaker_pipeline_template_v2.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + fmt.Sprintf(`
+resource "spinnaker_pipeline_template_v2" "test" {
+	template_id = "my-template"
+	template    = %q
+}
+`, testPipelineTemplateV2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "template_id", "my-template"),
+					resource.TestCheckResourceAttr(resourceName, "reference", "spinnaker://my-template"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourcePipelineTemplateV2CRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipelineTemplateV2()
+
+	raw := map[string]interface{}{
+		"template_id": "my-template",
+		"template":    testPipelineTemplateV2,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.Equal(t, "my-template", data.Id())
+	require.Equal(t, "spinnaker://my-template", data.Get("reference"))
+
+	template, ok := srv.PipelineTemplateV2("my-template", "")
+	require.True(t, ok)
+	require.Equal(t, "bar", template["metadata"].(map[string]interface{})["name"])
+
+	raw["template"] = `{"schema":"v2","pipeline":{"stages":[]},"metadata":{"name":"qux","description":"baz","scopes":["global"]}}`
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	template, _ = srv.PipelineTemplateV2("my-template", "")
+	require.Equal(t, "qux", template["metadata"].(map[string]interface{})["name"])
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.PipelineTemplateV2("my-template", "")
+	require.False(t, ok)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Empty(t, data.Id())
+}
+
+func testPipelineTemplateV2Named(name string) string {
+	return `{"schema":"v2","pipeline":{"stages":[]},"metadata":{"name":"` + name + `","description":"baz","scopes":["global"]}}`
+}
+
+func TestResourcePipelineTemplateV2Tags(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipelineTemplateV2()
+
+	resources := make(map[string]*schema.ResourceData)
+	for _, tag := range []string{"stable", "unstable"} {
+		data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"template_id": "my-template",
+			"tag":         tag,
+			"template":    testPipelineTemplateV2Named(tag),
+		})
+		require.False(t, r.CreateContext(ctx, data, meta).HasError())
+		resources[tag] = data
+	}
+
+	untagged := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"template_id": "other-template",
+		"template":    testPipelineTemplateV2Named("untagged"),
+	})
+	require.False(t, r.CreateContext(ctx, untagged, meta).HasError())
+
+	// Refreshing reads back the version each resource published.
+	for tag, data := range resources {
+		require.False(t, r.ReadContext(ctx, data, meta).HasError())
+		require.Equal(t, "my-template:"+tag, data.Id())
+		require.Equal(t, "spinnaker://my-template:"+tag, data.Get("reference"))
+		require.Contains(t, data.Get("template"), `"name":"`+tag+`"`)
+
+		template, _ := srv.PipelineTemplateV2("my-template", tag)
+		require.Equal(t, template["digest"], data.Get("digest"))
+		require.Equal(t, 3, data.Get("versions.#"))
+	}
+
+	require.False(t, r.ReadContext(ctx, untagged, meta).HasError())
+	require.Contains(t, untagged.Get("template"), `"name":"untagged"`)
+
+	// Tagged and untagged versions of the same template would overwrite
+	// each other's latest version.
+	diags := r.CreateContext(ctx, schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"template_id": "my-template",
+		"template":    testPipelineTemplateV2,
+	}), meta)
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, `is published with tags ["stable" "unstable"]`)
+
+	diags = r.CreateContext(ctx, schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"template_id": "other-template",
+		"tag":         "stable",
+		"template":    testPipelineTemplateV2,
+	}), meta)
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, "is published without a tag")
+
+	require.False(t, r.ReadContext(ctx, untagged, meta).HasError())
+	require.Contains(t, untagged.Get("template"), `"name":"untagged"`)
+
+	// Deleting a tagged version keeps the other versions, the latest version
+	// is deleted with the last tag.
+	require.False(t, r.DeleteContext(ctx, resources["stable"], meta).HasError())
+
+	_, ok := srv.PipelineTemplateV2("my-template", "stable")
+	require.False(t, ok)
+
+	_, ok = srv.PipelineTemplateV2("my-template", "unstable")
+	require.True(t, ok)
+
+	require.False(t, r.DeleteContext(ctx, resources["unstable"], meta).HasError())
+
+	_, ok = srv.PipelineTemplateV2("my-template", "")
+	require.False(t, ok)
+
+	_, ok = srv.PipelineTemplateV2("other-template", "")
+	require.True(t, ok)
+}
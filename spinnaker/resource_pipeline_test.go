@@ -0,0 +1,297 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/internal/fakegate"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcePipelineImport(t *testing.T) {
+	srv := newTestFakeGate(t)
+	id := srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "Deploy to prod"})
+
+	for _, importID := range []string{"myapp/Deploy to prod", id} {
+		data := resourcePipeline().TestResourceData()
+		data.SetId(importID)
+
+		result, err := resourcePipelineImport(context.Background(), data, newTestClientConfig(srv))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, id, result[0].Id())
+		require.Equal(t, "myapp", result[0].Get("application"))
+		require.Equal(t, "Deploy to prod", result[0].Get("name"))
+	}
+}
+
+func TestResourcePipeline_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+	srv.PutApplication("myapp", map[string]interface{}{"email": "team@example.com"})
+
+	resourceName := "spinnaker_pipeline.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_pipeline" "test" {
+	application = "myapp"
+	name        = "deploy"
+	pipeline    = jsonencode({ keepWaitingPipelines = false, stages = [] })
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "application", "myapp"),
+					resource.TestCheckResourceAttr(resourceName, "name", "deploy"),
+					resource.TestCheckResourceAttrSet(resourceName, "pipeline_id"),
+				),
+			},
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_pipeline" "test" {
+	application = "myapp"
+	name        = "deploy"
+	pipeline    = jsonencode({ keepWaitingPipelines = true, stages = [] })
+}
+`,
+				Check: resource.TestCheckResourceAttr(resourceName, "pipeline", `{"keepWaitingPipelines":true,"stages":[]}`),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "myapp/deploy",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourcePipelineCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipeline()
+
+	raw := map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"keepWaitingPipelines":false,"stages":[]}`,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	pipeline, ok := srv.Pipeline("myapp", "deploy")
+	require.True(t, ok)
+	require.Equal(t, pipeline["id"], data.Id())
+	require.Equal(t, data.Id(), data.Get("pipeline_id"))
+	require.Equal(t, false, pipeline["keepWaitingPipelines"])
+
+	raw["pipeline"] = `{"keepWaitingPipelines":true,"stages":[]}`
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.Equal(t, true, pipeline["keepWaitingPipelines"])
+	require.Equal(t, `{"keepWaitingPipelines":true,"stages":[]}`, data.Get("pipeline"))
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Pipeline("myapp", "deploy")
+	require.False(t, ok)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Empty(t, data.Id())
+}
+
+func TestResourcePipelineReadRetriesEOF(t *testing.T) {
+	srv := newTestFakeGate(t)
+	id := srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "deploy"})
+	srv.InjectFault(fakegate.Fault{
+		Path:  "/applications/myapp/pipelineConfigs/deploy",
+		EOF:   true,
+		Times: 1,
+	})
+
+	r := resourcePipeline()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+	})
+
+	require.False(t, r.ReadContext(context.Background(), data, newTestClientConfig(srv)).HasError())
+	require.Equal(t, id, data.Id())
+}
+
+func TestResourcePipelineReadByIDKeepsStateOnEOF(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipeline()
+
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"stages":[]}`,
+	})
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	id := data.Id()
+	srv.InjectFault(fakegate.Fault{Path: "/pipelineConfigs/" + id + "/history", EOF: true})
+
+	require.True(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Equal(t, id, data.Id())
+}
+
+func TestResourcePipelineRenameKeepsID(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourcePipeline()
+
+	raw := map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"stages":[]}`,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	id := data.Id()
+
+	raw["name"] = "deploy-prod"
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+	require.Equal(t, id, data.Id())
+
+	_, ok := srv.Pipeline("myapp", "deploy")
+	require.False(t, ok)
+
+	pipeline, ok := srv.Pipeline("myapp", "deploy-prod")
+	require.True(t, ok)
+	require.Equal(t, id, pipeline["id"])
+
+	// Renames outside of terraform are picked up by reading the pipeline by ID.
+	pipeline["name"] = "deploy-eu"
+	srv.PutPipeline(pipeline)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Equal(t, id, data.Id())
+	require.Equal(t, "deploy-eu", data.Get("name"))
+}
+
+func TestResourcePipelineCreateAdoptsExisting(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	id := srv.PutPipeline(map[string]interface{}{"application": "myapp", "name": "deploy"})
+
+	r := resourcePipeline()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"keepWaitingPipelines":true,"stages":[]}`,
+	})
+	require.False(t, r.CreateContext(ctx, data, newTestClientConfig(srv)).HasError())
+	require.Equal(t, id, data.Id())
+
+	pipeline, _ := srv.Pipeline("myapp", "deploy")
+	require.Equal(t, true, pipeline["keepWaitingPipelines"])
+}
+
+func TestResourcePipelineManualEdit(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	srv.SetUser("terraform")
+	meta := newTestClientConfig(srv)
+	r := resourcePipeline()
+
+	raw := map[string]interface{}{
+		"application":         "myapp",
+		"name":                "deploy",
+		"pipeline":            `{"stages":[]}`,
+		"fail_on_manual_edit": true,
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.Equal(t, "terraform", data.Get("last_modified_by"))
+	require.NotEmpty(t, data.Get("update_ts"))
+
+	// Refreshing a pipeline last saved by terraform does not warn.
+	require.Empty(t, r.ReadContext(ctx, data, meta))
+
+	// Make sure the edit gets a new timestamp.
+	time.Sleep(2 * time.Millisecond)
+
+	pipeline, _ := srv.Pipeline("myapp", "deploy")
+	pipeline["lastModifiedBy"] = "someone"
+	pipeline["description"] = "edited in Deck"
+	srv.PutPipeline(pipeline)
+
+	diags := r.ReadContext(ctx, data, meta)
+	require.Len(t, diags, 1)
+	require.Equal(t, diag.Warning, diags[0].Severity)
+	require.Contains(t, diags[0].Detail, `last modified by "someone"`)
+	require.Equal(t, "someone", data.Get("last_modified_by"))
+
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	diags = r.UpdateContext(ctx, data, meta)
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, "refusing to update")
+
+	raw["fail_on_manual_edit"] = false
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+	require.Equal(t, "terraform", data.Get("last_modified_by"))
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.NotContains(t, pipeline, "description")
+}
+
+func TestResourcePipelineManualEditUserLookupFailure(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	srv.SetUser("terraform")
+	meta := newTestClientConfig(srv)
+	r := resourcePipeline()
+
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"pipeline":    `{"stages":[]}`,
+	})
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	editPipeline := func(description string) {
+		// Make sure the edit gets a new timestamp.
+		time.Sleep(2 * time.Millisecond)
+
+		pipeline, _ := srv.Pipeline("myapp", "deploy")
+		pipeline["lastModifiedBy"] = "someone"
+		pipeline["description"] = description
+		srv.PutPipeline(pipeline)
+	}
+
+	editPipeline("edited in Deck")
+	srv.InjectFault(fakegate.Fault{Path: "/auth/user", StatusCode: 403, Times: 1})
+
+	diags := r.ReadContext(ctx, data, meta)
+	require.Len(t, diags, 1)
+	require.Equal(t, diag.Warning, diags[0].Severity)
+	require.Contains(t, diags[0].Summary, "Skipped check for manual edits")
+
+	// Failed lookups are not cached.
+	editPipeline("edited in Deck again")
+
+	diags = r.ReadContext(ctx, data, meta)
+	require.Len(t, diags, 1)
+	require.Contains(t, diags[0].Detail, `last modified by "someone"`)
+}
@@ -0,0 +1,289 @@
+package spinnaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceProject() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides a project, which groups applications, clusters and pipelines on a dashboard.",
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "Name of the project.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"email": {
+				Description: "Email of the project owner.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"applications": {
+				Description: "Applications that belong to the project.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"cluster": {
+				Description: "Clusters shown on the project dashboard.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"account": {
+							Description: "Account of the clusters.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"stack": {
+							Description: "Stack of the clusters, `*` matches all stacks. Defaults to `*`.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "*",
+						},
+						"detail": {
+							Description: "Detail of the clusters, `*` matches all details. Defaults to `*`.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "*",
+						},
+						"applications": {
+							Description: "Applications to show clusters of. Defaults to all applications of the project.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"pipeline_config": {
+				Description: "Pipelines shown on the project dashboard.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"application": {
+							Description: "Application of the pipeline.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"pipeline_config_id": {
+							Description: "ID of the pipeline.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			// Projects can be imported by ID or name, as Gate looks them up
+			// by either. Read replaces a name with the project ID.
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CreateContext: resourceProjectCreate,
+		ReadContext:   resourceProjectRead,
+		UpdateContext: resourceProjectUpdate,
+		DeleteContext: resourceProjectDelete,
+	}
+}
+
+type projectRead struct {
+	ID     string
+	Name   string
+	Email  string
+	Config struct {
+		Applications    []string
+		Clusters        []projectCluster
+		PipelineConfigs []projectPipelineConfig
+	}
+}
+
+type projectCluster struct {
+	Account      string
+	Stack        string
+	Detail       string
+	Applications []string
+}
+
+type projectPipelineConfig struct {
+	Application      string
+	PipelineConfigID string
+}
+
+func resourceProjectCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := api.SaveProject(ctx, client, expandProject(data), data.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceProjectRead(ctx, data, meta)
+}
+
+func resourceProjectRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The project ID is not known until the project has been created.
+	projectIDOrName := data.Id()
+	if projectIDOrName == "" {
+		projectIDOrName = data.Get("name").(string)
+	}
+
+	var project projectRead
+
+	err = api.GetProject(ctx, client, projectIDOrName, &project)
+	if apierrors.IsNotFound(err) {
+		data.SetId("")
+		return nil
+	} else if err != nil {
+		return diag.Errorf("failed to fetch project %q: %s", projectIDOrName, err)
+	}
+
+	return diag.FromErr(readProject(data, project))
+}
+
+func resourceProjectUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	project := expandProject(data)
+	project["id"] = data.Id()
+
+	if err := api.SaveProject(ctx, client, project, data.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceProjectRead(ctx, data, meta)
+}
+
+func resourceProjectDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	projectName := data.Get("name").(string)
+
+	if err := api.DeleteProject(ctx, client, data.Id(), projectName, data.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func expandProject(data *schema.ResourceData) map[string]interface{} {
+	clusters := make([]interface{}, 0)
+	for _, c := range data.Get("cluster").([]interface{}) {
+		cluster := c.(map[string]interface{})
+
+		result := map[string]interface{}{
+			"account": cluster["account"].(string),
+			"stack":   cluster["stack"].(string),
+			"detail":  cluster["detail"].(string),
+		}
+
+		// Spinnaker shows clusters of all project applications if the
+		// applications of a cluster are not set.
+		if applications := cluster["applications"].([]interface{}); len(applications) > 0 {
+			result["applications"] = applications
+		}
+
+		clusters = append(clusters, result)
+	}
+
+	pipelineConfigs := make([]interface{}, 0)
+	for _, p := range data.Get("pipeline_config").([]interface{}) {
+		pipelineConfig := p.(map[string]interface{})
+
+		pipelineConfigs = append(pipelineConfigs, map[string]interface{}{
+			"application":      pipelineConfig["application"].(string),
+			"pipelineConfigId": pipelineConfig["pipeline_config_id"].(string),
+		})
+	}
+
+	return map[string]interface{}{
+		"name":  data.Get("name").(string),
+		"email": data.Get("email").(string),
+		"config": map[string]interface{}{
+			"applications":    data.Get("applications").([]interface{}),
+			"clusters":        clusters,
+			"pipelineConfigs": pipelineConfigs,
+		},
+	}
+}
+
+func readProject(data *schema.ResourceData, project projectRead) error {
+	if err := data.Set("name", project.Name); err != nil {
+		return err
+	}
+
+	if err := data.Set("email", project.Email); err != nil {
+		return err
+	}
+
+	if err := data.Set("applications", project.Config.Applications); err != nil {
+		return err
+	}
+
+	clusters := make([]interface{}, 0, len(project.Config.Clusters))
+	for _, cluster := range project.Config.Clusters {
+		clusters = append(clusters, map[string]interface{}{
+			"account":      cluster.Account,
+			"stack":        cluster.Stack,
+			"detail":       cluster.Detail,
+			"applications": cluster.Applications,
+		})
+	}
+
+	if err := data.Set("cluster", clusters); err != nil {
+		return err
+	}
+
+	pipelineConfigs := make([]interface{}, 0, len(project.Config.PipelineConfigs))
+	for _, pipelineConfig := range project.Config.PipelineConfigs {
+		pipelineConfigs = append(pipelineConfigs, map[string]interface{}{
+			"application":        pipelineConfig.Application,
+			"pipeline_config_id": pipelineConfig.PipelineConfigID,
+		})
+	}
+
+	if err := data.Set("pipeline_config", pipelineConfigs); err != nil {
+		return err
+	}
+
+	data.SetId(project.ID)
+
+	return nil
+}
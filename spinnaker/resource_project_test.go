@@ -0,0 +1,127 @@
+package spinnaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceProject_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+
+	resourceName := "spinnaker_project.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_project" "test" {
+	name         = "myproject"
+	email        = "team@example.com"
+	applications = ["myapp"]
+
+	cluster {
+		account = "prod"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "myproject"),
+					resource.TestCheckResourceAttr(resourceName, "cluster.0.stack", "*"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceProjectCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourceProject()
+
+	raw := map[string]interface{}{
+		"name":         "myproject",
+		"email":        "team@example.com",
+		"applications": []interface{}{"myapp", "otherapp"},
+		"cluster": []interface{}{
+			map[string]interface{}{
+				"account": "prod",
+				"stack":   "web",
+			},
+		},
+		"pipeline_config": []interface{}{
+			map[string]interface{}{
+				"application":        "myapp",
+				"pipeline_config_id": "4a3b7b5c",
+			},
+		},
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.NotEmpty(t, data.Id())
+
+	project, ok := srv.Project("myproject")
+	require.True(t, ok)
+	require.Equal(t, data.Id(), project["id"])
+	require.Equal(t, map[string]interface{}{
+		"applications": []interface{}{"myapp", "otherapp"},
+		"clusters": []interface{}{
+			map[string]interface{}{"account": "prod", "stack": "web", "detail": "*"},
+		},
+		"pipelineConfigs": []interface{}{
+			map[string]interface{}{"application": "myapp", "pipelineConfigId": "4a3b7b5c"},
+		},
+	}, project["config"])
+	require.Equal(t, "*", data.Get("cluster.0.detail"))
+
+	id := data.Id()
+
+	raw["name"] = "renamed"
+	raw["email"] = "ops@example.com"
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+	require.Equal(t, id, data.Id())
+
+	project, _ = srv.Project(id)
+	require.Equal(t, "renamed", project["name"])
+	require.Equal(t, "ops@example.com", project["email"])
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Project(id)
+	require.False(t, ok)
+
+	require.False(t, r.ReadContext(ctx, data, meta).HasError())
+	require.Empty(t, data.Id())
+}
+
+func TestResourceProjectCreateDuplicateName(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourceProject()
+
+	raw := map[string]interface{}{
+		"name":  "myproject",
+		"email": "team@example.com",
+	}
+
+	require.False(t, r.CreateContext(ctx, schema.TestResourceDataRaw(t, r.Schema, raw), meta).HasError())
+
+	diags := r.CreateContext(ctx, schema.TestResourceDataRaw(t, r.Schema, raw), meta)
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, "already exists")
+}
@@ -0,0 +1,790 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceStructuredPipeline() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides a pipeline that is described by typed blocks instead of a single JSON document.",
+		Schema: map[string]*schema.Schema{
+			"application": {
+				Description: "Name of the application the pipeline belongs to.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"name": {
+				Description: "Name of the pipeline.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "Description of the pipeline.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"disabled": {
+				Description: "Whether the pipeline is disabled.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"limit_concurrent": {
+				Description: "Disable concurrent pipeline executions (only run one at a time).",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"keep_waiting_pipelines": {
+				Description: "Do not automatically cancel pipelines waiting in queue.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"stage": {
+				Description: "Stages of the pipeline.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ref_id": {
+							Description: "Unique reference ID of the stage within the pipeline.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"type": {
+							Description: "Type of the stage, e.g. `wait` or `deployManifest`.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"name": {
+							Description: "Name of the stage.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"requisite_stage_ref_ids": {
+							Description: "Reference IDs of the stages that must complete before this stage runs.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"config": {
+							Description:      "Stage type specific configuration as JSON object.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentStageConfigDiffs,
+						},
+					},
+				},
+			},
+			"trigger": {
+				Description: "Triggers of the pipeline.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description: "Type of the trigger, e.g. `cron`, `git` or `pipeline`.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"enabled": {
+							Description: "Whether the trigger is enabled.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+						},
+						"config": {
+							Description:      "Trigger type specific configuration as JSON object.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentJSONObjectDiffs,
+						},
+					},
+				},
+			},
+			"parameter": {
+				Description: "Parameters of the pipeline.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the parameter.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"label": {
+							Description: "Label of the parameter shown in Deck.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"description": {
+							Description: "Description of the parameter.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"default": {
+							Description: "Default value of the parameter.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"required": {
+							Description: "Whether the parameter is required.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"pinned": {
+							Description: "Whether the parameter is always shown in the execution details.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"options": {
+							Description: "Allowed values of the parameter.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"notification": {
+				Description: "Notifications sent for pipeline events.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description: "Type of the notification, e.g. `slack` or `email`.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"address": {
+							Description: "Address to send the notification to, e.g. a Slack channel or an email address.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"level": {
+							Description: "Level of the notification.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "pipeline",
+						},
+						"when": {
+							Description: "Pipeline events to notify about, e.g. `pipeline.failed`.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"config": {
+							Description:      "Additional notification configuration as JSON object, e.g. custom messages.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentJSONObjectDiffs,
+						},
+					},
+				},
+			},
+			"expected_artifact": {
+				Description: "Artifacts the pipeline expects to be present in its execution context.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "Unique ID of the expected artifact.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"display_name": {
+							Description: "Name of the expected artifact shown in Deck.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"match_artifact": {
+							Description:      "Artifact the incoming artifacts are matched against as JSON object.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentJSONObjectDiffs,
+						},
+						"default_artifact": {
+							Description:      "Artifact used if no incoming artifact matches as JSON object.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentJSONObjectDiffs,
+						},
+						"use_default_artifact": {
+							Description: "Use `default_artifact` if no incoming artifact matches.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"use_prior_artifact": {
+							Description: "Use the artifact of the previous execution if no incoming artifact matches.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			"pipeline_id": {
+				Description: "ID of the pipeline.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineImport,
+		},
+		CreateContext: resourceStructuredPipelineCreate,
+		ReadContext:   resourceStructuredPipelineRead,
+		UpdateContext: resourceStructuredPipelineUpdate,
+		DeleteContext: resourceStructuredPipelineDelete,
+	}
+}
+
+func resourceStructuredPipelineCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+
+	pipeline, err := expandStructuredPipeline(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := createPipeline(ctx, client, applicationName, pipelineName, pipeline); err != nil {
+		return diag.Errorf("failed to create pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	return resourceStructuredPipelineRead(ctx, data, meta)
+}
+
+func resourceStructuredPipelineRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+
+	var p pipelineRead
+
+	pipeline, err := getPipeline(ctx, client, data, &p)
+	if apierrors.IsNotFound(err) {
+		data.SetId("")
+		return nil
+	} else if err != nil {
+		return diag.Errorf("failed to fetch pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	if err := data.Set("application", p.Application); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("name", p.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := flattenStructuredPipeline(data, pipeline); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("pipeline_id", p.ID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(p.ID)
+
+	return nil
+}
+
+func resourceStructuredPipelineUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+	pipelineID := data.Get("pipeline_id").(string)
+
+	pipeline, err := expandStructuredPipeline(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipeline["id"] = pipelineID
+
+	if err := api.UpdatePipeline(ctx, client, pipelineID, pipeline); err != nil {
+		return diag.Errorf("failed to update pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	return resourceStructuredPipelineRead(ctx, data, meta)
+}
+
+func resourceStructuredPipelineDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourcePipelineDelete(ctx, data, meta)
+}
+
+// expandStructuredPipeline builds the pipeline JSON sent to Spinnaker from the
+// typed blocks of data. Keys of the JSON config attributes are merged into
+// the objects they belong to, with typed attributes taking precedence.
+func expandStructuredPipeline(data *schema.ResourceData) (map[string]interface{}, error) {
+	pipeline := map[string]interface{}{
+		"application":          data.Get("application").(string),
+		"name":                 data.Get("name").(string),
+		"description":          data.Get("description").(string),
+		"disabled":             data.Get("disabled").(bool),
+		"limitConcurrent":      data.Get("limit_concurrent").(bool),
+		"keepWaitingPipelines": data.Get("keep_waiting_pipelines").(bool),
+	}
+
+	stages := make([]interface{}, 0)
+	for i, s := range data.Get("stage").([]interface{}) {
+		stage := s.(map[string]interface{})
+
+		result, err := parseJSONObject(stage["config"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("stage.%d.config: %w", i, err)
+		}
+
+		result["refId"] = stage["ref_id"].(string)
+		result["type"] = stage["type"].(string)
+		result["name"] = stage["name"].(string)
+		result["requisiteStageRefIds"] = stage["requisite_stage_ref_ids"].([]interface{})
+
+		stages = append(stages, result)
+	}
+
+	pipeline["stages"] = stages
+
+	triggers := make([]interface{}, 0)
+	for i, t := range data.Get("trigger").([]interface{}) {
+		trigger := t.(map[string]interface{})
+
+		result, err := parseJSONObject(trigger["config"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("trigger.%d.config: %w", i, err)
+		}
+
+		result["type"] = trigger["type"].(string)
+		result["enabled"] = trigger["enabled"].(bool)
+
+		triggers = append(triggers, result)
+	}
+
+	pipeline["triggers"] = triggers
+
+	parameters := make([]interface{}, 0)
+	for _, p := range data.Get("parameter").([]interface{}) {
+		parameter := p.(map[string]interface{})
+
+		options := make([]interface{}, 0)
+		for _, o := range parameter["options"].([]interface{}) {
+			options = append(options, map[string]interface{}{"value": o})
+		}
+
+		parameters = append(parameters, map[string]interface{}{
+			"name":        parameter["name"].(string),
+			"label":       parameter["label"].(string),
+			"description": parameter["description"].(string),
+			"default":     parameter["default"].(string),
+			"required":    parameter["required"].(bool),
+			"pinned":      parameter["pinned"].(bool),
+			"hasOptions":  len(options) > 0,
+			"options":     options,
+		})
+	}
+
+	pipeline["parameterConfig"] = parameters
+
+	notifications := make([]interface{}, 0)
+	for i, n := range data.Get("notification").([]interface{}) {
+		notification := n.(map[string]interface{})
+
+		result, err := parseJSONObject(notification["config"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("notification.%d.config: %w", i, err)
+		}
+
+		result["type"] = notification["type"].(string)
+		result["address"] = notification["address"].(string)
+		result["level"] = notification["level"].(string)
+		result["when"] = notification["when"].([]interface{})
+
+		notifications = append(notifications, result)
+	}
+
+	pipeline["notifications"] = notifications
+
+	artifacts := make([]interface{}, 0)
+	for i, a := range data.Get("expected_artifact").([]interface{}) {
+		artifact := a.(map[string]interface{})
+
+		matchArtifact, err := parseJSONObject(artifact["match_artifact"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("expected_artifact.%d.match_artifact: %w", i, err)
+		}
+
+		defaultArtifact, err := parseJSONObject(artifact["default_artifact"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("expected_artifact.%d.default_artifact: %w", i, err)
+		}
+
+		artifacts = append(artifacts, map[string]interface{}{
+			"id":                 artifact["id"].(string),
+			"displayName":        artifact["display_name"].(string),
+			"matchArtifact":      matchArtifact,
+			"defaultArtifact":    defaultArtifact,
+			"useDefaultArtifact": artifact["use_default_artifact"].(bool),
+			"usePriorArtifact":   artifact["use_prior_artifact"].(bool),
+		})
+	}
+
+	pipeline["expectedArtifacts"] = artifacts
+
+	return pipeline, nil
+}
+
+// flattenStructuredPipeline sets the typed blocks of data from the pipeline
+// JSON returned by Spinnaker. Keys that are not represented by typed
+// attributes are collected in the JSON config attributes.
+func flattenStructuredPipeline(data *schema.ResourceData, pipeline map[string]interface{}) error {
+	description, _ := pipeline["description"].(string)
+	disabled, _ := pipeline["disabled"].(bool)
+	limitConcurrent := boolValue(pipeline["limitConcurrent"], true)
+	keepWaitingPipelines, _ := pipeline["keepWaitingPipelines"].(bool)
+
+	if err := data.Set("description", description); err != nil {
+		return err
+	}
+
+	if err := data.Set("disabled", disabled); err != nil {
+		return err
+	}
+
+	if err := data.Set("limit_concurrent", limitConcurrent); err != nil {
+		return err
+	}
+
+	if err := data.Set("keep_waiting_pipelines", keepWaitingPipelines); err != nil {
+		return err
+	}
+
+	blocks := []struct {
+		key     string
+		flatten func(map[string]interface{}) ([]interface{}, error)
+	}{
+		{key: "stage", flatten: flattenPipelineStages},
+		{key: "trigger", flatten: flattenPipelineTriggers},
+		{key: "parameter", flatten: flattenPipelineParameters},
+		{key: "notification", flatten: flattenPipelineNotifications},
+		{key: "expected_artifact", flatten: flattenPipelineExpectedArtifacts},
+	}
+
+	for _, block := range blocks {
+		values, err := block.flatten(pipeline)
+		if err != nil {
+			return err
+		}
+
+		if err := data.Set(block.key, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func flattenPipelineStages(pipeline map[string]interface{}) ([]interface{}, error) {
+	var stages []interface{}
+	for _, s := range objectList(pipeline["stages"]) {
+		stage := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			stage[k] = v
+		}
+
+		// Keys Spinnaker fills in with their defaults are left out, so that
+		// they don't show up as changes of the configured stage config.
+		removeStageDefaults(stage, stringValue(s["type"]))
+
+		config, err := encodeRemainingKeys(stage, "refId", "type", "name", "requisiteStageRefIds")
+		if err != nil {
+			return nil, err
+		}
+
+		stages = append(stages, map[string]interface{}{
+			"ref_id":                  stringValue(s["refId"]),
+			"type":                    stringValue(s["type"]),
+			"name":                    stringValue(s["name"]),
+			"requisite_stage_ref_ids": s["requisiteStageRefIds"],
+			"config":                  config,
+		})
+	}
+
+	return stages, nil
+}
+
+func flattenPipelineTriggers(pipeline map[string]interface{}) ([]interface{}, error) {
+	var triggers []interface{}
+	for _, t := range objectList(pipeline["triggers"]) {
+		config, err := encodeRemainingKeys(t, "type", "enabled")
+		if err != nil {
+			return nil, err
+		}
+
+		triggers = append(triggers, map[string]interface{}{
+			"type":    stringValue(t["type"]),
+			"enabled": boolValue(t["enabled"], true),
+			"config":  config,
+		})
+	}
+
+	return triggers, nil
+}
+
+func flattenPipelineParameters(pipeline map[string]interface{}) ([]interface{}, error) {
+	var parameters []interface{}
+	for _, p := range objectList(pipeline["parameterConfig"]) {
+		var options []interface{}
+		for _, o := range objectList(p["options"]) {
+			options = append(options, stringValue(o["value"]))
+		}
+
+		required, _ := p["required"].(bool)
+		pinned, _ := p["pinned"].(bool)
+
+		parameters = append(parameters, map[string]interface{}{
+			"name":        stringValue(p["name"]),
+			"label":       stringValue(p["label"]),
+			"description": stringValue(p["description"]),
+			"default":     stringValue(p["default"]),
+			"required":    required,
+			"pinned":      pinned,
+			"options":     options,
+		})
+	}
+
+	return parameters, nil
+}
+
+func flattenPipelineNotifications(pipeline map[string]interface{}) ([]interface{}, error) {
+	var notifications []interface{}
+	for _, n := range objectList(pipeline["notifications"]) {
+		config, err := encodeRemainingKeys(n, "type", "address", "level", "when")
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, map[string]interface{}{
+			"type":    stringValue(n["type"]),
+			"address": stringValue(n["address"]),
+			"level":   stringValue(n["level"]),
+			"when":    n["when"],
+			"config":  config,
+		})
+	}
+
+	return notifications, nil
+}
+
+func flattenPipelineExpectedArtifacts(pipeline map[string]interface{}) ([]interface{}, error) {
+	var artifacts []interface{}
+	for _, a := range objectList(pipeline["expectedArtifacts"]) {
+		matchArtifact, err := encodeJSONObject(a["matchArtifact"])
+		if err != nil {
+			return nil, err
+		}
+
+		defaultArtifact, err := encodeJSONObject(a["defaultArtifact"])
+		if err != nil {
+			return nil, err
+		}
+
+		useDefaultArtifact, _ := a["useDefaultArtifact"].(bool)
+		usePriorArtifact, _ := a["usePriorArtifact"].(bool)
+
+		artifacts = append(artifacts, map[string]interface{}{
+			"id":                   stringValue(a["id"]),
+			"display_name":         stringValue(a["displayName"]),
+			"match_artifact":       matchArtifact,
+			"default_artifact":     defaultArtifact,
+			"use_default_artifact": useDefaultArtifact,
+			"use_prior_artifact":   usePriorArtifact,
+		})
+	}
+
+	return artifacts, nil
+}
+
+// objectList returns the JSON objects contained in the JSON array v. Other
+// array elements are skipped.
+func objectList(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	objects := make([]map[string]interface{}, 0, len(items))
+
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects
+}
+
+// stringValue returns v formatted as string. Returns an empty string if v is
+// nil.
+func stringValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		// Avoid the exponent format for large numbers like timestamps.
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// boolValue returns v if it is a bool, or defaultValue otherwise.
+func boolValue(v interface{}, defaultValue bool) bool {
+	if value, ok := v.(bool); ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// encodeRemainingKeys encodes all keys of object except the given ones as
+// JSON object. Returns an empty string if there are no remaining keys.
+func encodeRemainingKeys(object map[string]interface{}, keys ...string) (string, error) {
+	remaining := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		remaining[k] = v
+	}
+
+	for _, key := range keys {
+		delete(remaining, key)
+	}
+
+	return encodeJSONObject(remaining)
+}
+
+// encodeJSONObject encodes v as JSON. Returns an empty string if v is nil or
+// an empty object.
+func encodeJSONObject(v interface{}) (string, error) {
+	if object, ok := v.(map[string]interface{}); v == nil || (ok && len(object) == 0) {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal json: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+// parseJSONObject parses raw as JSON object. An empty string yields an empty
+// object.
+func parseJSONObject(raw string) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+
+	if strings.TrimSpace(raw) == "" {
+		return object, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		return nil, fmt.Errorf("invalid json object: %w", err)
+	}
+
+	if object == nil {
+		object = make(map[string]interface{})
+	}
+
+	return object, nil
+}
+
+func validateJSONObject(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parseJSONObject(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: %w", key, err))
+	}
+
+	return
+}
+
+func suppressEquivalentJSONObjectDiffs(k, old, new string, d *schema.ResourceData) bool {
+	oldObject, err := parseJSONObject(old)
+	if err != nil {
+		return false
+	}
+
+	newObject, err := parseJSONObject(new)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(oldObject, newObject)
+}
+
+// suppressEquivalentStageConfigDiffs suppresses diffs of stage configs that
+// only differ in keys set to the defaults Spinnaker fills in for the stage
+// type.
+func suppressEquivalentStageConfigDiffs(k, old, new string, d *schema.ResourceData) bool {
+	oldObject, err := parseJSONObject(old)
+	if err != nil {
+		return false
+	}
+
+	newObject, err := parseJSONObject(new)
+	if err != nil {
+		return false
+	}
+
+	var stageType string
+	if d != nil {
+		stageType, _ = d.Get(strings.TrimSuffix(k, "config") + "type").(string)
+	}
+
+	removeStageDefaults(oldObject, stageType)
+	removeStageDefaults(newObject, stageType)
+
+	return reflect.DeepEqual(oldObject, newObject)
+}
@@ -0,0 +1,215 @@
+package spinnaker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceStructuredPipeline_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+
+	resourceName := "spinnaker_structured_pipeline.test"
+
+	config := func(waitTime int) string {
+		return testUnitProviderConfig(srv) + `
+resource "spinnaker_structured_pipeline" "test" {
+	application = "myapp"
+	name        = "deploy"
+
+	stage {
+		ref_id = "1"
+		type   = "wait"
+		name   = "Wait"
+		config = jsonencode({ waitTime = ` + fmt.Sprint(waitTime) + ` })
+	}
+
+	trigger {
+		type   = "cron"
+		config = jsonencode({ cronExpression = "0 0 * * * ?" })
+	}
+}
+`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config(30),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "stage.0.type", "wait"),
+					resource.TestCheckResourceAttr(resourceName, "stage.0.config", `{"waitTime":30}`),
+					resource.TestCheckResourceAttr(resourceName, "trigger.0.enabled", "true"),
+				),
+			},
+			{
+				Config: config(60),
+				Check:  resource.TestCheckResourceAttr(resourceName, "stage.0.config", `{"waitTime":60}`),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "myapp/deploy",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceStructuredPipelineCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourceStructuredPipeline()
+
+	raw := map[string]interface{}{
+		"application": "myapp",
+		"name":        "deploy",
+		"description": "Deploys myapp",
+		"stage": []interface{}{
+			map[string]interface{}{
+				"ref_id": "1",
+				"type":   "wait",
+				"name":   "Wait",
+				"config": `{"waitTime": 30}`,
+			},
+			map[string]interface{}{
+				"ref_id":                  "2",
+				"type":                    "manualJudgment",
+				"name":                    "Approve",
+				"requisite_stage_ref_ids": []interface{}{"1"},
+			},
+		},
+		"trigger": []interface{}{
+			map[string]interface{}{
+				"type":   "cron",
+				"config": `{"cronExpression": "0 0 * * * ?"}`,
+			},
+		},
+		"parameter": []interface{}{
+			map[string]interface{}{
+				"name":     "version",
+				"default":  "latest",
+				"required": true,
+				"options":  []interface{}{"latest", "stable"},
+			},
+		},
+		"notification": []interface{}{
+			map[string]interface{}{
+				"type":    "slack",
+				"address": "#deployments",
+				"when":    []interface{}{"pipeline.failed"},
+			},
+		},
+		"expected_artifact": []interface{}{
+			map[string]interface{}{
+				"id":             "image",
+				"display_name":   "Image",
+				"match_artifact": `{"type": "docker/image", "name": "myorg/myapp"}`,
+			},
+		},
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	pipeline, ok := srv.Pipeline("myapp", "deploy")
+	require.True(t, ok)
+	require.Equal(t, pipeline["id"], data.Id())
+	require.Equal(t, "Deploys myapp", pipeline["description"])
+	require.Equal(t, true, pipeline["limitConcurrent"])
+
+	stages := pipeline["stages"].([]interface{})
+	require.Len(t, stages, 2)
+	require.Equal(t, map[string]interface{}{
+		"refId":                "1",
+		"type":                 "wait",
+		"name":                 "Wait",
+		"requisiteStageRefIds": []interface{}{},
+		"waitTime":             float64(30),
+	}, stages[0])
+	require.Equal(t, []interface{}{"1"}, stages[1].(map[string]interface{})["requisiteStageRefIds"])
+
+	trigger := pipeline["triggers"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "0 0 * * * ?", trigger["cronExpression"])
+	require.Equal(t, true, trigger["enabled"])
+
+	parameter := pipeline["parameterConfig"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, true, parameter["hasOptions"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"value": "latest"},
+		map[string]interface{}{"value": "stable"},
+	}, parameter["options"])
+
+	require.Equal(t, `{"waitTime":30}`, data.Get("stage.0.config"))
+	require.Equal(t, "", data.Get("stage.1.config"))
+	require.Equal(t, []interface{}{"latest", "stable"}, data.Get("parameter.0.options"))
+	require.Equal(t, "#deployments", data.Get("notification.0.address"))
+	require.Equal(t, `{"name":"myorg/myapp","type":"docker/image"}`, data.Get("expected_artifact.0.match_artifact"))
+
+	raw["stage"].([]interface{})[0].(map[string]interface{})["config"] = `{"waitTime": 60}`
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.True(t, data.HasChange("stage.0.config"))
+	require.False(t, data.HasChange("stage.1"))
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.Equal(t, float64(60), pipeline["stages"].([]interface{})[0].(map[string]interface{})["waitTime"])
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Pipeline("myapp", "deploy")
+	require.False(t, ok)
+}
+
+func TestSuppressEquivalentJSONObjectDiffs(t *testing.T) {
+	require.True(t, suppressEquivalentJSONObjectDiffs("", "", "{}", nil))
+	require.True(t, suppressEquivalentJSONObjectDiffs("", `{"a":1,"b":2}`, `{"b": 2, "a": 1}`, nil))
+	require.False(t, suppressEquivalentJSONObjectDiffs("", `{"a":1}`, `{"a":2}`, nil))
+	require.False(t, suppressEquivalentJSONObjectDiffs("", `{"a":1}`, `{invalid`, nil))
+}
+
+func TestSuppressEquivalentStageConfigDiffs(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceStructuredPipeline().Schema, map[string]interface{}{
+		"stage": []interface{}{
+			map[string]interface{}{"ref_id": "1", "type": "jenkins", "name": "Build"},
+		},
+	})
+
+	require.True(t, suppressEquivalentStageConfigDiffs("stage.0.config", `{"failPipeline":true,"isNew":true}`, "", d))
+	require.True(t, suppressEquivalentStageConfigDiffs("stage.0.config", `{"job":"build","waitForCompletion":true}`, `{"job":"build"}`, d))
+	require.False(t, suppressEquivalentStageConfigDiffs("stage.0.config", `{"waitForCompletion":false}`, "", d))
+	require.False(t, suppressEquivalentStageConfigDiffs("stage.0.config", `{"job":"build"}`, `{"job":"test"}`, d))
+}
+
+func TestFlattenStructuredPipelineDefaults(t *testing.T) {
+	r := resourceStructuredPipeline()
+	data := r.TestResourceData()
+
+	require.NoError(t, flattenStructuredPipeline(data, map[string]interface{}{
+		"stages": []interface{}{
+			map[string]interface{}{
+				"refId":        "1",
+				"type":         "pipeline",
+				"name":         "Trigger",
+				"failPipeline": true,
+				"isNew":        true,
+				"pipeline":     "deploy",
+			},
+		},
+		"triggers": []interface{}{
+			map[string]interface{}{"type": "cron"},
+		},
+	}))
+
+	require.Equal(t, true, data.Get("limit_concurrent"))
+	require.Equal(t, false, data.Get("disabled"))
+	require.Equal(t, true, data.Get("trigger.0.enabled"))
+	require.Equal(t, `{"pipeline":"deploy"}`, data.Get("stage.0.config"))
+}
@@ -0,0 +1,563 @@
+package spinnaker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api"
+	apierrors "github.com/aegaxs/terraform-provider-spinnaker/spinnaker/api/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	gate "github.com/spinnaker/spin/cmd/gateclient"
+)
+
+func resourceTemplatedPipeline() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides a pipeline that is an instance of a V2 pipeline template.",
+		Schema: map[string]*schema.Schema{
+			"application": {
+				Description: "Name of the application the pipeline belongs to.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"name": {
+				Description: "Name of the pipeline.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"template_reference": {
+				Description: "Reference of the V2 pipeline template, e.g. `spinnaker://my-template:stable`.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"variables": {
+				Description: "Values of the template variables. Values are converted to the types the template declares, e.g. `\"3\"` for an `int` variable or a JSON array for a `list` variable.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"exclude": {
+				Description: "Reference IDs of the template stages to leave out of the pipeline.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"inherit": {
+				Description: "Template attributes the pipeline inherits, any of `triggers`, `parameters`, `notifications` and `expectedArtifacts`.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"triggers", "parameters", "notifications", "expectedArtifacts"}, false),
+				},
+			},
+			"stage": {
+				Description: "Stages injected into the stages of the template.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ref_id": {
+							Description: "Unique reference ID of the stage within the pipeline.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"type": {
+							Description: "Type of the stage, e.g. `wait` or `deployManifest`.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"name": {
+							Description: "Name of the stage.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"config": {
+							Description:      "Stage type specific configuration as JSON object.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateJSONObject,
+							DiffSuppressFunc: suppressEquivalentJSONObjectDiffs,
+						},
+						"inject": {
+							Description: "Where to inject the stage. Stages without `inject` block are passed to Spinnaker as they are.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							MaxItems:    1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"before": {
+										Description: "Reference IDs of the stages to run the stage before.",
+										Type:        schema.TypeList,
+										Optional:    true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"after": {
+										Description: "Reference IDs of the stages to run the stage after.",
+										Type:        schema.TypeList,
+										Optional:    true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"first": {
+										Description: "Run the stage before all other stages.",
+										Type:        schema.TypeBool,
+										Optional:    true,
+										Default:     false,
+									},
+									"last": {
+										Description: "Run the stage after all other stages.",
+										Type:        schema.TypeBool,
+										Optional:    true,
+										Default:     false,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"pipeline_id": {
+				Description: "ID of the pipeline.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineImport,
+		},
+		CustomizeDiff: resourceTemplatedPipelineCustomizeDiff,
+		CreateContext: resourceTemplatedPipelineCreate,
+		ReadContext:   resourceTemplatedPipelineRead,
+		UpdateContext: resourceTemplatedPipelineUpdate,
+		DeleteContext: resourceTemplatedPipelineDelete,
+	}
+}
+
+// resourceTemplatedPipelineCustomizeDiff validates the variables against the
+// template at plan time. The check is skipped if the template reference is
+// not known yet or the template does not exist yet, e.g. because it is
+// created in the same apply; create and update validate again.
+func resourceTemplatedPipelineCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	// The SDK drops unknown map values and only marks the size of the map
+	// as unknown.
+	if !diff.NewValueKnown("template_reference") || !diff.NewValueKnown("variables.%") {
+		return nil
+	}
+
+	client, err := meta.(*clientConfig).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = expandTemplatedPipelineVariables(ctx, client,
+		diff.Get("template_reference").(string), diff.Get("variables").(map[string]interface{}))
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+func resourceTemplatedPipelineCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+
+	pipeline, err := expandTemplatedPipeline(ctx, client, data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := createPipeline(ctx, client, applicationName, pipelineName, pipeline); err != nil {
+		return diag.Errorf("failed to create pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	return resourceTemplatedPipelineRead(ctx, data, meta)
+}
+
+func resourceTemplatedPipelineRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+
+	var p pipelineRead
+
+	pipeline, err := getPipeline(ctx, client, data, &p)
+	if apierrors.IsNotFound(err) {
+		data.SetId("")
+		return nil
+	} else if err != nil {
+		return diag.Errorf("failed to fetch pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	if err := data.Set("application", p.Application); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("name", p.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := flattenTemplatedPipeline(data, pipeline); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("pipeline_id", p.ID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(p.ID)
+
+	return nil
+}
+
+func resourceTemplatedPipelineUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientConfig := meta.(*clientConfig)
+
+	client, err := clientConfig.Client()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applicationName := data.Get("application").(string)
+	pipelineName := data.Get("name").(string)
+	pipelineID := data.Get("pipeline_id").(string)
+
+	pipeline, err := expandTemplatedPipeline(ctx, client, data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipeline["id"] = pipelineID
+
+	if err := api.UpdatePipeline(ctx, client, pipelineID, pipeline); err != nil {
+		return diag.Errorf("failed to update pipeline %q for application %q: %s",
+			pipelineName, applicationName, err)
+	}
+
+	return resourceTemplatedPipelineRead(ctx, data, meta)
+}
+
+func resourceTemplatedPipelineDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourcePipelineDelete(ctx, data, meta)
+}
+
+// expandTemplatedPipeline builds the pipeline instance JSON sent to Spinnaker
+// from data, with the variables converted to the types declared by the
+// template.
+func expandTemplatedPipeline(ctx context.Context, client *gate.GatewayClient, data *schema.ResourceData) (map[string]interface{}, error) {
+	reference := data.Get("template_reference").(string)
+
+	variables, err := expandTemplatedPipelineVariables(ctx, client, reference, data.Get("variables").(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	stages := make([]interface{}, 0)
+	for i, s := range data.Get("stage").([]interface{}) {
+		stage := s.(map[string]interface{})
+
+		result, err := parseJSONObject(stage["config"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("stage.%d.config: %w", i, err)
+		}
+
+		result["refId"] = stage["ref_id"].(string)
+		result["type"] = stage["type"].(string)
+		result["name"] = stage["name"].(string)
+
+		if inject := stage["inject"].([]interface{}); len(inject) > 0 && inject[0] != nil {
+			in := inject[0].(map[string]interface{})
+
+			result["inject"] = map[string]interface{}{
+				"before": in["before"].([]interface{}),
+				"after":  in["after"].([]interface{}),
+				"first":  in["first"].(bool),
+				"last":   in["last"].(bool),
+			}
+		}
+
+		stages = append(stages, result)
+	}
+
+	return map[string]interface{}{
+		"application": data.Get("application").(string),
+		"name":        data.Get("name").(string),
+		"schema":      "v2",
+		"type":        "templatedPipeline",
+		"template": map[string]interface{}{
+			"artifactAccount": "front50ArtifactCredentials",
+			"reference":       reference,
+			"type":            "front50/pipelineTemplate",
+		},
+		"variables": variables,
+		"exclude":   data.Get("exclude").([]interface{}),
+		"inherit":   data.Get("inherit").([]interface{}),
+		"stages":    stages,
+	}, nil
+}
+
+// expandTemplatedPipelineVariables converts the raw variable values to the
+// types the template referenced by reference declares. It fails if a value
+// does not match its type, a variable is not declared or a variable without
+// default value is missing. Values are passed as strings if the template is
+// not stored in Spinnaker.
+func expandTemplatedPipelineVariables(ctx context.Context, client *gate.GatewayClient, reference string, raw map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(raw))
+
+	templateID, tag, digest, ok := parsePipelineTemplateV2Reference(reference)
+	if !ok {
+		for name, value := range raw {
+			variables[name] = value
+		}
+
+		return variables, nil
+	}
+
+	template, err := api.GetPipelineTemplateV2(ctx, client, templateID, tag, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pipeline template %q: %w", reference, err)
+	}
+
+	declared := make(map[string]api.PipelineTemplateV2Variable, len(template.Variables))
+	for _, variable := range template.Variables {
+		declared[variable.Name] = variable
+	}
+
+	var errs *multierror.Error
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		variable, ok := declared[name]
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf("variables.%s: not declared by template %q", name, reference))
+			continue
+		}
+
+		value, err := convertTemplateVariable(variable.Type, raw[name].(string))
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("variables.%s: %w", name, err))
+			continue
+		}
+
+		variables[name] = value
+	}
+
+	for _, variable := range template.Variables {
+		if _, ok := raw[variable.Name]; !ok && variable.DefaultValue == nil {
+			errs = multierror.Append(errs, fmt.Errorf("variables.%s: required by template %q", variable.Name, reference))
+		}
+	}
+
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
+
+// convertTemplateVariable converts raw to the V2 template variable type
+// variableType. Unknown types are passed as string.
+func convertTemplateVariable(variableType, raw string) (interface{}, error) {
+	switch variableType {
+	case "int":
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid int", raw)
+		}
+		return value, nil
+	case "float":
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid float", raw)
+		}
+		return value, nil
+	case "boolean":
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid boolean", raw)
+		}
+		return value, nil
+	case "list":
+		var value []interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			return nil, fmt.Errorf("%q is not a valid JSON array", raw)
+		}
+		return value, nil
+	case "object":
+		var value map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			return nil, fmt.Errorf("%q is not a valid JSON object", raw)
+		}
+		return value, nil
+	}
+
+	return raw, nil
+}
+
+// parsePipelineTemplateV2Reference parses references of the form
+// spinnaker://<template ID>, spinnaker://<template ID>:<tag> or
+// spinnaker://<template ID>@sha256:<digest>. Returns false for references to
+// templates that are not stored in Spinnaker.
+func parsePipelineTemplateV2Reference(reference string) (templateID, tag, digest string, ok bool) {
+	if !strings.HasPrefix(reference, "spinnaker://") {
+		return "", "", "", false
+	}
+
+	id := strings.TrimPrefix(reference, "spinnaker://")
+	if id == "" {
+		return "", "", "", false
+	}
+
+	if templateID, digest, ok := strings.Cut(id, "@"); ok {
+		return templateID, "", strings.TrimPrefix(digest, "sha256:"), true
+	}
+
+	templateID, tag = parsePipelineTemplateV2ID(id)
+
+	return templateID, tag, "", true
+}
+
+// flattenTemplatedPipeline sets the attributes of data from the pipeline
+// instance JSON returned by Spinnaker.
+func flattenTemplatedPipeline(data *schema.ResourceData, pipeline map[string]interface{}) error {
+	template, _ := pipeline["template"].(map[string]interface{})
+
+	if err := data.Set("template_reference", stringValue(template["reference"])); err != nil {
+		return err
+	}
+
+	rawVariables, _ := pipeline["variables"].(map[string]interface{})
+
+	variables, err := flattenTemplatedPipelineVariables(rawVariables, data.Get("variables").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+
+	if err := data.Set("variables", variables); err != nil {
+		return err
+	}
+
+	if err := data.Set("exclude", pipeline["exclude"]); err != nil {
+		return err
+	}
+
+	if err := data.Set("inherit", pipeline["inherit"]); err != nil {
+		return err
+	}
+
+	var stages []interface{}
+	for _, s := range objectList(pipeline["stages"]) {
+		config, err := encodeRemainingKeys(s, "refId", "type", "name", "inject")
+		if err != nil {
+			return err
+		}
+
+		var inject []interface{}
+		if in, ok := s["inject"].(map[string]interface{}); ok {
+			first, _ := in["first"].(bool)
+			last, _ := in["last"].(bool)
+
+			inject = append(inject, map[string]interface{}{
+				"before": in["before"],
+				"after":  in["after"],
+				"first":  first,
+				"last":   last,
+			})
+		}
+
+		stages = append(stages, map[string]interface{}{
+			"ref_id": stringValue(s["refId"]),
+			"type":   stringValue(s["type"]),
+			"name":   stringValue(s["name"]),
+			"config": config,
+			"inject": inject,
+		})
+	}
+
+	return data.Set("stage", stages)
+}
+
+// flattenTemplatedPipelineVariables returns the variable values of a pipeline
+// as strings. String values are returned as is, other values are encoded as
+// JSON unless the prior value converts to the same value, so that e.g. "1.0"
+// for a float variable does not cause a diff.
+func flattenTemplatedPipelineVariables(variables, prior map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(variables))
+
+	for name, value := range variables {
+		if s, ok := value.(string); ok {
+			result[name] = s
+			continue
+		}
+
+		if p, ok := prior[name].(string); ok {
+			if converted, err := convertTemplateVariable(templateVariableType(value), p); err == nil &&
+				reflect.DeepEqual(converted, value) {
+				result[name] = p
+				continue
+			}
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal variable %q: %w", name, err)
+		}
+
+		result[name] = string(encoded)
+	}
+
+	return result, nil
+}
+
+// templateVariableType returns the V2 template variable type of a value
+// decoded from JSON.
+func templateVariableType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return "string"
+}
@@ -0,0 +1,345 @@
+package spinnaker
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplatedPipelineTemplate = `{"schema":"v2","pipeline":{"stages":[{"refId":"wait","type":"wait"}]},` +
+	`"metadata":{"name":"deploy","description":"Deploys an app","scopes":["global"]},` +
+	`"variables":[{"name":"replicas","type":"int","defaultValue":1},{"name":"regions","type":"list","defaultValue":[]},{"name":"image","type":"string"}]}`
+
+func testCreatePipelineTemplateV2(t *testing.T, meta *clientConfig, templateID, tag, template string) {
+	r := resourcePipelineTemplateV2()
+	data := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"template_id": templateID,
+		"tag":         tag,
+		"template":    template,
+	})
+	require.False(t, r.CreateContext(context.Background(), data, meta).HasError())
+}
+
+func TestParsePipelineTemplateV2Reference(t *testing.T) {
+	for reference, expected := range map[string][]string{
+		"spinnaker://deploy":                {"deploy", "", ""},
+		"spinnaker://deploy:stable":         {"deploy", "stable", ""},
+		"spinnaker://deploy@sha256:abcdef0": {"deploy", "", "abcdef0"},
+	} {
+		templateID, tag, digest, ok := parsePipelineTemplateV2Reference(reference)
+		require.True(t, ok, reference)
+		require.Equal(t, expected, []string{templateID, tag, digest}, reference)
+	}
+
+	_, _, _, ok := parsePipelineTemplateV2Reference("https://example.com/template.json")
+	require.False(t, ok)
+}
+
+func TestConvertTemplateVariable(t *testing.T) {
+	for _, tc := range []struct {
+		variableType string
+		raw          string
+		expected     interface{}
+		err          string
+	}{
+		{variableType: "string", raw: "nginx", expected: "nginx"},
+		{variableType: "int", raw: "3", expected: int64(3)},
+		{variableType: "int", raw: "three", err: `"three" is not a valid int`},
+		{variableType: "float", raw: "0.5", expected: 0.5},
+		{variableType: "boolean", raw: "true", expected: true},
+		{variableType: "boolean", raw: "yes", err: `"yes" is not a valid boolean`},
+		{variableType: "list", raw: `["a","b"]`, expected: []interface{}{"a", "b"}},
+		{variableType: "list", raw: `{}`, err: `"{}" is not a valid JSON array`},
+		{variableType: "object", raw: `{"a":1}`, expected: map[string]interface{}{"a": float64(1)}},
+		{variableType: "object", raw: `null`, err: `"null" is not a valid JSON object`},
+	} {
+		value, err := convertTemplateVariable(tc.variableType, tc.raw)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, value)
+	}
+}
+
+func TestFlattenTemplatedPipelineVariables(t *testing.T) {
+	variables, err := flattenTemplatedPipelineVariables(map[string]interface{}{
+		"version":  "1.1",
+		"count":    "1000",
+		"ratio":    float64(2),
+		"enabled":  true,
+		"replicas": float64(4),
+		"regions":  []interface{}{"us-east-1"},
+	}, map[string]interface{}{
+		"version":  "1.10",
+		"count":    "1e3",
+		"ratio":    "2.0",
+		"enabled":  "True",
+		"replicas": "3",
+		"regions":  `[ "us-east-1" ]`,
+	})
+	require.NoError(t, err)
+
+	// String values are never treated as equivalent to a different prior
+	// value, other values keep the prior notation if it converts to the
+	// same value.
+	require.Equal(t, map[string]interface{}{
+		"version":  "1.1",
+		"count":    "1000",
+		"ratio":    "2.0",
+		"enabled":  "True",
+		"replicas": "4",
+		"regions":  `[ "us-east-1" ]`,
+	}, variables)
+}
+
+func TestExpandTemplatedPipelineVariables(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	testCreatePipelineTemplateV2(t, meta, "deploy", "stable", testTemplatedPipelineTemplate)
+
+	client, err := meta.Client()
+	require.NoError(t, err)
+
+	variables, err := expandTemplatedPipelineVariables(ctx, client, "spinnaker://deploy:stable", map[string]interface{}{
+		"image":    "nginx",
+		"replicas": "3",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"image": "nginx", "replicas": int64(3)}, variables)
+
+	_, err = expandTemplatedPipelineVariables(ctx, client, "spinnaker://deploy:stable", map[string]interface{}{
+		"replicas": "three",
+		"unknown":  "value",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `variables.replicas: "three" is not a valid int`)
+	require.Contains(t, err.Error(), `variables.unknown: not declared by template "spinnaker://deploy:stable"`)
+	require.Contains(t, err.Error(), `variables.image: required by template "spinnaker://deploy:stable"`)
+
+	variables, err = expandTemplatedPipelineVariables(ctx, client, "https://example.com/template.json", map[string]interface{}{
+		"replicas": "3",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"replicas": "3"}, variables)
+}
+
+func TestResourceTemplatedPipelineCRUD(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	testCreatePipelineTemplateV2(t, meta, "deploy", "stable", testTemplatedPipelineTemplate)
+
+	// Skip the plan time validation to cover the validation on apply.
+	r := resourceTemplatedPipeline()
+	r.CustomizeDiff = nil
+
+	raw := map[string]interface{}{
+		"application":        "myapp",
+		"name":               "deploy",
+		"template_reference": "spinnaker://deploy:stable",
+		"variables": map[string]interface{}{
+			"image":   "nginx",
+			"regions": `["us-east-1"]`,
+		},
+		"exclude": []interface{}{"wait"},
+		"inherit": []interface{}{"triggers"},
+		"stage": []interface{}{
+			map[string]interface{}{
+				"ref_id": "approve",
+				"type":   "manualJudgment",
+				"name":   "Approve",
+				"config": `{"judgmentInputs":[]}`,
+				"inject": []interface{}{
+					map[string]interface{}{"first": true},
+				},
+			},
+		},
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+	require.NotEmpty(t, data.Id())
+
+	pipeline, ok := srv.Pipeline("myapp", "deploy")
+	require.True(t, ok)
+	require.Equal(t, "v2", pipeline["schema"])
+	require.Equal(t, "templatedPipeline", pipeline["type"])
+	require.Equal(t, "spinnaker://deploy:stable", pipeline["template"].(map[string]interface{})["reference"])
+	require.Equal(t, map[string]interface{}{
+		"image":   "nginx",
+		"regions": []interface{}{"us-east-1"},
+	}, pipeline["variables"])
+	require.Equal(t, map[string]interface{}{
+		"before": []interface{}{},
+		"after":  []interface{}{},
+		"first":  true,
+		"last":   false,
+	}, pipeline["stages"].([]interface{})[0].(map[string]interface{})["inject"])
+
+	require.Equal(t, `["us-east-1"]`, data.Get("variables.regions"))
+	require.Equal(t, `{"judgmentInputs":[]}`, data.Get("stage.0.config"))
+	require.Equal(t, true, data.Get("stage.0.inject.0.first"))
+
+	raw["variables"] = map[string]interface{}{
+		"image":    "1.10",
+		"replicas": "3",
+	}
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.Equal(t, map[string]interface{}{
+		"image":    "1.10",
+		"replicas": float64(3),
+	}, pipeline["variables"])
+	require.Equal(t, "3", data.Get("variables.replicas"))
+
+	// Changes of string variables are applied even if the values are
+	// equal as numbers.
+	raw["variables"] = map[string]interface{}{
+		"image":    "1.1",
+		"replicas": "3",
+	}
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.True(t, data.HasChange("variables.image"))
+	require.False(t, r.UpdateContext(ctx, data, meta).HasError())
+
+	pipeline, _ = srv.Pipeline("myapp", "deploy")
+	require.Equal(t, "1.1", pipeline["variables"].(map[string]interface{})["image"])
+	require.Equal(t, "1.1", data.Get("variables.image"))
+
+	raw["variables"] = map[string]interface{}{"replicas": "three"}
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	diags := r.UpdateContext(ctx, data, meta)
+	require.True(t, diags.HasError())
+	require.Contains(t, diags[0].Summary, `"three" is not a valid int`)
+
+	require.False(t, r.DeleteContext(ctx, data, meta).HasError())
+
+	_, ok = srv.Pipeline("myapp", "deploy")
+	require.False(t, ok)
+}
+
+func TestResourceTemplatedPipelineCustomizeDiff(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	r := resourceTemplatedPipeline()
+
+	raw := map[string]interface{}{
+		"application":        "myapp",
+		"name":               "deploy",
+		"template_reference": "spinnaker://deploy",
+		"variables":          map[string]interface{}{"replicas": "three"},
+	}
+
+	// The template may be created in the same apply.
+	_, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	require.NoError(t, err)
+
+	testCreatePipelineTemplateV2(t, meta, "deploy", "", testTemplatedPipelineTemplate)
+
+	_, err = r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"three" is not a valid int`)
+
+	raw["variables"] = map[string]interface{}{"image": "nginx", "replicas": "3"}
+	_, err = r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	require.NoError(t, err)
+
+	// Values read from other resources are only validated once known. The
+	// placeholder is the value terraform uses for unknown values.
+	raw["variables"] = map[string]interface{}{"image": "nginx", "replicas": "74D93920-ED26-11E3-AC10-0800200C9A66"}
+	_, err = r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	require.NoError(t, err)
+
+	raw["variables"] = "74D93920-ED26-11E3-AC10-0800200C9A66"
+	_, err = r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	require.NoError(t, err)
+}
+
+func TestResourceTemplatedPipeline_fakeGate(t *testing.T) {
+	srv := newTestFakeGate(t)
+	testCreatePipelineTemplateV2(t, newTestClientConfig(srv), "deploy", "", testTemplatedPipelineTemplate)
+
+	resourceName := "spinnaker_templated_pipeline.test"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testUnitPreCheck(t) },
+		ProviderFactories: testUnitProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_templated_pipeline" "test" {
+	application        = "myapp"
+	name               = "deploy"
+	template_reference = "spinnaker://deploy"
+
+	variables = {
+		image = "nginx"
+	}
+}
+`,
+				Check: resource.TestCheckResourceAttr(resourceName, "variables.image", "nginx"),
+			},
+			{
+				Config: testUnitProviderConfig(srv) + `
+resource "spinnaker_templated_pipeline" "test" {
+	application        = "myapp"
+	name               = "deploy"
+	template_reference = "spinnaker://deploy"
+
+	variables = {
+		replicas = "three"
+	}
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"three" is not a valid int`),
+			},
+		},
+	})
+}
+
+func TestResourceTemplatedPipelineStageWithoutInject(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestFakeGate(t)
+	meta := newTestClientConfig(srv)
+	testCreatePipelineTemplateV2(t, meta, "deploy", "stable", testTemplatedPipelineTemplate)
+
+	r := resourceTemplatedPipeline()
+	r.CustomizeDiff = nil
+
+	raw := map[string]interface{}{
+		"application":        "myapp",
+		"name":               "deploy",
+		"template_reference": "spinnaker://deploy:stable",
+		"variables":          map[string]interface{}{"image": "nginx"},
+		"stage": []interface{}{
+			map[string]interface{}{
+				"ref_id": "wait",
+				"type":   "wait",
+				"config": `{"waitTime":60}`,
+			},
+		},
+	}
+
+	data := schema.TestResourceDataRaw(t, r.Schema, raw)
+	require.False(t, r.CreateContext(ctx, data, meta).HasError())
+
+	pipeline, _ := srv.Pipeline("myapp", "deploy")
+	require.NotContains(t, pipeline["stages"].([]interface{})[0], "inject")
+	require.Empty(t, data.Get("stage.0.inject"))
+
+	data = testResourceDataUpdate(t, r, data.State(), raw)
+	require.False(t, data.HasChange("stage"))
+}